@@ -0,0 +1,316 @@
+package zl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BatchOptions is the settings of batching used by the HTTP sinks.
+// Entries are sent when the batch is full, at every Interval, and when Sync is called.
+// The entries that failed to be sent after the retries are kept while the server is unavailable,
+// and sent again with the next batch.
+type BatchOptions struct {
+	// Size is the maximum number of entries in a request. Default is 100.
+	Size int
+	// Interval is the maximum time an entry waits in the queue. Default is 1 second.
+	Interval time.Duration
+	// QueueSize is the maximum number of entries waiting to be sent. Default is 10000.
+	// Entries are dropped with an error when the queue is full.
+	// It also limits the entries kept after failing to be sent, and the oldest are dropped with an error.
+	QueueSize int
+	// MaxRetries is the number of retries when sending fails. Default is 3, and a negative value disables retries.
+	// Sync and Close try once without waiting for the retries.
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry. It doubles at every retry. Default is 1 second.
+	RetryBackoff time.Duration
+}
+
+func (o BatchOptions) withDefaults() BatchOptions {
+	if o.Size <= 0 {
+		o.Size = 100
+	}
+	if o.Interval <= 0 {
+		o.Interval = time.Second
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 10000
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = 3
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = time.Second
+	}
+	return o
+}
+
+// batchWriter is a zapcore.WriteSyncer that queues the entries and sends them in batches
+// from a goroutine.
+type batchWriter struct {
+	options BatchOptions
+	send    func(entries [][]byte) error
+	queue   chan []byte
+	flush   chan chan error
+	syncing chan struct{} // syncing stops waiting for the retries when Sync is called.
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+	pending [][]byte // pending is the entries that failed to be sent. It is only used in run.
+}
+
+func newBatchWriter(options BatchOptions, send func(entries [][]byte) error) *batchWriter {
+	options = options.withDefaults()
+	b := &batchWriter{
+		options: options,
+		send:    send,
+		queue:   make(chan []byte, options.QueueSize),
+		flush:   make(chan chan error),
+		syncing: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// Write implements io.Writer.
+func (b *batchWriter) Write(p []byte) (int, error) {
+	entry := make([]byte, len(p))
+	copy(entry, p)
+	select {
+	case <-b.done:
+		return 0, errors.New("the batch writer is closed")
+	default:
+	}
+	select {
+	case b.queue <- entry:
+		return len(p), nil
+	default:
+		return 0, fmt.Errorf("the queue is full. the entry is dropped (queue size: %d)", b.options.QueueSize)
+	}
+}
+
+// Sync implements zapcore.WriteSyncer. It sends the queued entries and waits for the result.
+// The entries failed to be sent are kept and retried later, so that Sync does not wait for the retries.
+func (b *batchWriter) Sync() error {
+	select {
+	case b.syncing <- struct{}{}:
+	default:
+	}
+	ch := make(chan error)
+	select {
+	case b.flush <- ch:
+		return <-ch
+	case <-b.stopped:
+		return nil
+	}
+}
+
+// Close sends the queued entries and stops the goroutine.
+func (b *batchWriter) Close() error {
+	b.once.Do(func() {
+		close(b.done)
+	})
+	<-b.stopped
+	return nil
+}
+
+func (b *batchWriter) run() {
+	defer close(b.stopped)
+	ticker := time.NewTicker(b.options.Interval)
+	defer ticker.Stop()
+
+	var batch [][]byte
+	for {
+		select {
+		case entry := <-b.queue:
+			batch = append(batch, entry)
+			if len(batch) >= b.options.Size {
+				b.logError(b.sendAll(batch, true))
+				batch = nil
+			}
+		case <-ticker.C:
+			b.logError(b.sendAll(batch, true))
+			batch = nil
+		case ch := <-b.flush:
+			select {
+			case <-b.syncing:
+			default:
+			}
+			ch <- b.sendAll(b.drain(batch), false)
+			batch = nil
+		case <-b.done:
+			b.logError(b.sendAll(b.drain(batch), false))
+			if len(b.pending) > 0 {
+				b.logError(fmt.Errorf("the batch writer is closed. %d entries are dropped", len(b.pending)))
+			}
+			return
+		}
+	}
+}
+
+// drain moves the queued entries to the batch.
+func (b *batchWriter) drain(batch [][]byte) [][]byte {
+	for {
+		select {
+		case entry := <-b.queue:
+			batch = append(batch, entry)
+		default:
+			return batch
+		}
+	}
+}
+
+// sendAll sends the pending entries and the entries split by the batch size.
+// When sending fails after the retries, the rest of the entries are kept to be sent with the next batch.
+// It retries only when retry is true.
+func (b *batchWriter) sendAll(entries [][]byte, retry bool) error {
+	entries = append(b.pending, entries...)
+	b.pending = nil
+	var errs []error
+	for len(entries) > 0 {
+		n := len(entries)
+		if n > b.options.Size {
+			n = b.options.Size
+		}
+		failed, err := b.sendWithRetry(entries[:n], retry)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		entries = entries[n:]
+		if len(failed) > 0 {
+			errs = append(errs, b.keep(append(failed[:len(failed):len(failed)], entries...)))
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// keep keeps the entries to be sent with the next batch. The oldest are dropped when they exceed QueueSize.
+func (b *batchWriter) keep(entries [][]byte) error {
+	var err error
+	if over := len(entries) - b.options.QueueSize; over > 0 {
+		entries = entries[over:]
+		err = fmt.Errorf("too many entries failed to be sent. %d entries are dropped (queue size: %d)", over, b.options.QueueSize)
+	}
+	b.pending = entries
+	return err
+}
+
+// sendWithRetry sends the entries and returns the entries failed to be sent after the retries.
+// The entries failed with permanentError are dropped.
+func (b *batchWriter) sendWithRetry(entries [][]byte, retry bool) ([][]byte, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	backoff := b.options.RetryBackoff
+	var err error
+	var dropped []error
+	for i := 0; ; i++ {
+		if err = b.send(entries); err == nil {
+			return nil, errors.Join(dropped...)
+		}
+		var partial *partialError
+		if errors.As(err, &partial) {
+			entries = partial.entries
+			if partial.dropped != nil {
+				dropped = append(dropped, partial.dropped)
+			}
+			if len(entries) == 0 {
+				return nil, errors.Join(dropped...)
+			}
+		}
+		var p *permanentError
+		if errors.As(err, &p) {
+			return nil, errors.Join(append(dropped, fmt.Errorf("failed to send %d entries: %w", len(entries), err))...)
+		}
+		if !retry || i >= b.options.MaxRetries || !b.wait(backoff) {
+			break
+		}
+		backoff *= 2
+	}
+	return entries, errors.Join(append(dropped, fmt.Errorf("failed to send %d entries. they are sent again with the next batch: %w", len(entries), err))...)
+}
+
+// wait waits for the backoff before retrying, and reports false when Close or Sync is called meanwhile.
+func (b *batchWriter) wait(backoff time.Duration) bool {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-b.done:
+		return false
+	case <-b.syncing:
+		return false
+	}
+}
+
+func (b *batchWriter) logError(err error) {
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// permanentError is an error that is not retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// partialError is an error that a part of the entries failed to be sent.
+// The entries are retried, and dropped is the error of the entries that cannot be retried.
+type partialError struct {
+	entries [][]byte
+	dropped error
+	err     error
+}
+
+func (e *partialError) Error() string {
+	return e.err.Error()
+}
+
+func (e *partialError) Unwrap() error {
+	return e.err
+}
+
+// postRequest sends the request and checks the response status.
+// 429 Too Many Requests and 5xx responses are retried, and the other error responses are not.
+func postRequest(client *http.Client, req *http.Request) error {
+	_, err := doRequest(client, req)
+	return err
+}
+
+// doRequest sends the request and returns the response body.
+func doRequest(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusMultipleChoices {
+		return io.ReadAll(resp.Body)
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("%s %s: %s: %s", req.Method, req.URL, resp.Status, body)
+	if isRetryableStatus(resp.StatusCode) {
+		return nil, err
+	}
+	return nil, &permanentError{err: err}
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
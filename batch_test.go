@@ -0,0 +1,197 @@
+package zl
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testSender struct {
+	mu      sync.Mutex
+	batches [][]string
+	errs    []error
+}
+
+func (s *testSender) send(entries [][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return err
+	}
+	var batch []string
+	for _, e := range entries {
+		batch = append(batch, string(e))
+	}
+	s.batches = append(s.batches, batch)
+	return nil
+}
+
+func (s *testSender) get() [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.batches
+}
+
+func TestBatchWriter(t *testing.T) {
+	s := &testSender{}
+	b := newBatchWriter(BatchOptions{Size: 2, Interval: time.Hour}, s.send)
+	defer b.Close()
+
+	for _, e := range []string{"a", "b", "c"} {
+		_, err := b.Write([]byte(e))
+		assert.NoError(t, err)
+	}
+	assert.Eventually(t, func() bool { return len(s.get()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, [][]string{{"a", "b"}}, s.get())
+
+	assert.NoError(t, b.Sync())
+	assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, s.get())
+}
+
+func TestBatchWriter_interval(t *testing.T) {
+	s := &testSender{}
+	b := newBatchWriter(BatchOptions{Interval: 10 * time.Millisecond}, s.send)
+	defer b.Close()
+
+	_, err := b.Write([]byte("a"))
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return len(s.get()) == 1 }, time.Second, 10*time.Millisecond)
+}
+
+func TestBatchWriter_Close(t *testing.T) {
+	s := &testSender{}
+	b := newBatchWriter(BatchOptions{Interval: time.Hour}, s.send)
+	_, err := b.Write([]byte("a"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, b.Close())
+	assert.NoError(t, b.Close())
+	assert.Equal(t, [][]string{{"a"}}, s.get())
+	assert.NoError(t, b.Sync())
+	_, err = b.Write([]byte("b"))
+	assert.Error(t, err)
+}
+
+func TestBatchWriter_queueFull(t *testing.T) {
+	block := make(chan struct{})
+	b := newBatchWriter(BatchOptions{Size: 1, QueueSize: 1}, func(entries [][]byte) error {
+		<-block
+		return nil
+	})
+	defer b.Close()
+	defer close(block)
+
+	var err error
+	for i := 0; i < 3 && err == nil; i++ {
+		_, err = b.Write([]byte("a"))
+	}
+	assert.ErrorContains(t, err, "the queue is full")
+}
+
+func TestBatchWriter_retry(t *testing.T) {
+	s := &testSender{errs: []error{errors.New("error 1"), errors.New("error 2")}}
+	b := newBatchWriter(BatchOptions{Size: 1, Interval: time.Hour, RetryBackoff: time.Millisecond}, s.send)
+	defer b.Close()
+
+	// the full batch is retried in the goroutine.
+	_, err := b.Write([]byte("a"))
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return len(s.get()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, [][]string{{"a"}}, s.get())
+
+	// the permanent error is not retried.
+	s = &testSender{errs: []error{&permanentError{err: errors.New("bad request")}}}
+	b2 := newBatchWriter(BatchOptions{Interval: time.Hour}, s.send)
+	defer b2.Close()
+	_, err = b2.Write([]byte("b"))
+	assert.NoError(t, err)
+	assert.ErrorContains(t, b2.Sync(), "failed to send 1 entries: bad request")
+	assert.NoError(t, b2.Sync())
+	assert.Empty(t, s.get())
+}
+
+func TestBatchWriter_outage(t *testing.T) {
+	unavailable := errors.New("unavailable")
+	s := &testSender{errs: []error{unavailable}}
+	b := newBatchWriter(BatchOptions{Interval: time.Hour, QueueSize: 2}, s.send)
+	defer b.Close()
+
+	_, err := b.Write([]byte("a"))
+	assert.NoError(t, err)
+	assert.ErrorContains(t, b.Sync(), "failed to send 1 entries. they are sent again with the next batch: unavailable")
+	assert.Empty(t, s.get())
+
+	_, err = b.Write([]byte("b"))
+	assert.NoError(t, err)
+	assert.NoError(t, b.Sync())
+	assert.Equal(t, [][]string{{"a", "b"}}, s.get())
+
+	s.errs = []error{unavailable, unavailable}
+	for _, e := range []string{"c", "d"} {
+		_, err := b.Write([]byte(e))
+		assert.NoError(t, err)
+	}
+	assert.ErrorContains(t, b.Sync(), "failed to send 2 entries")
+	_, err = b.Write([]byte("e"))
+	assert.NoError(t, err)
+	assert.ErrorContains(t, b.Sync(), "1 entries are dropped (queue size: 2)")
+	assert.NoError(t, b.Sync())
+	assert.Equal(t, [][]string{{"a", "b"}, {"d", "e"}}, s.get())
+}
+
+func TestBatchWriter_retryInterrupted(t *testing.T) {
+	var calls atomic.Int32
+	b := newBatchWriter(BatchOptions{Size: 1, Interval: time.Hour}, func(entries [][]byte) error {
+		calls.Add(1)
+		return errors.New("unavailable")
+	})
+
+	// Sync and Close do not wait for the backoff of the retries with the defaults.
+	_, err := b.Write([]byte("a"))
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+	start := time.Now()
+	assert.ErrorContains(t, b.Sync(), "they are sent again with the next batch")
+	assert.NoError(t, b.Close())
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func Test_postRequest(t *testing.T) {
+	var status atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+		_, _ = w.Write([]byte("message"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		status    int
+		err       bool
+		permanent bool
+	}{
+		{http.StatusOK, false, false},
+		{http.StatusNoContent, false, false},
+		{http.StatusBadRequest, true, true},
+		{http.StatusTooManyRequests, true, false},
+		{http.StatusServiceUnavailable, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			status.Store(int32(tt.status))
+			req, err := http.NewRequest(http.MethodPost, server.URL, nil)
+			assert.NoError(t, err)
+			err = postRequest(server.Client(), req)
+			assert.Equal(t, tt.err, err != nil)
+			var p *permanentError
+			assert.Equal(t, tt.permanent, errors.As(err, &p))
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package zl
+
+import (
+	"log"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	isBuffered    bool
+	bufferSize    int
+	flushInterval time.Duration
+	buffers       []*zapcore.BufferedWriteSyncer // buffers are created while the loggers are built. See: state
+)
+
+// SetBufferedWrite enables buffering of the writes to the log files.
+// Entries are written to the file when the buffer is full, at every flushInterval,
+// and when Sync or SyncWhenStop is called, or before exiting by Fatal.
+// Zero values use zap's defaults (256 kB and 30 seconds).
+// See: https://pkg.go.dev/go.uber.org/zap/zapcore#BufferedWriteSyncer
+func SetBufferedWrite(size int, interval time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	isBuffered = true
+	bufferSize = size
+	flushInterval = interval
+}
+
+// withBuffer wraps the WriteSyncer with zapcore.BufferedWriteSyncer when SetBufferedWrite is set.
+func withBuffer(ws zapcore.WriteSyncer) zapcore.WriteSyncer {
+	if !isBuffered {
+		return ws
+	}
+	b := &zapcore.BufferedWriteSyncer{WS: ws, Size: bufferSize, FlushInterval: flushInterval}
+	buffers = append(buffers, b)
+	return b
+}
+
+// syncBuffers flushes the buffered writes to the log files.
+func (s *state) syncBuffers() {
+	s.use(func() {
+		for _, b := range s.buffers {
+			if err := b.Sync(); err != nil {
+				log.Println(err)
+			}
+		}
+	})
+}
+
+// stopBuffers flushes the buffered writes and stops the goroutines flushing periodically.
+func (s *state) stopBuffers() {
+	for _, b := range s.buffers {
+		if err := b.Stop(); err != nil {
+			log.Println(err)
+		}
+	}
+}
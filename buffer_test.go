@@ -0,0 +1,72 @@
+package zl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestSetBufferedWrite(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "app.jsonl")
+	SetOutput(FileOutput)
+	SetRotateFileName(fileName)
+	SetBufferedWrite(1024, time.Hour)
+	defer ResetGlobalLoggerSettings()
+	Init()
+	assert.Len(t, buffers, 1)
+
+	Info("BUFFERED_MESSAGE")
+	bytes, err := os.ReadFile(fileName)
+	assert.True(t, os.IsNotExist(err) || len(bytes) == 0)
+
+	Sync()
+	bytes, err = os.ReadFile(fileName)
+	assert.NoError(t, err)
+	assert.Contains(t, string(bytes), "BUFFERED_MESSAGE")
+}
+
+func TestSetBufferedWrite_fatal(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "app.jsonl")
+	SetIsTest()
+	SetOutput(ConsoleAndFileOutput)
+	SetRotateFileName(fileName)
+	SetBufferedWrite(0, 0)
+	defer ResetGlobalLoggerSettings()
+	Init()
+
+	Fatal("FATAL_MESSAGE")
+	bytes, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	assert.Contains(t, string(bytes), "FATAL_MESSAGE")
+}
+
+func benchmarkFileOutput(b *testing.B, buffered bool) {
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(b.TempDir(), "app.jsonl"))
+	if buffered {
+		SetBufferedWrite(0, 0)
+	}
+	defer ResetGlobalLoggerSettings()
+	Init()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			Info("BENCHMARK", zap.String("user_name", "Alice"), zap.Int("user_age", 20))
+		}
+	})
+}
+
+func BenchmarkFileOutput(b *testing.B) {
+	benchmarkFileOutput(b, false)
+}
+
+func BenchmarkFileOutput_buffered(b *testing.B) {
+	benchmarkFileOutput(b, true)
+}
@@ -0,0 +1,156 @@
+package zl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ElasticsearchOptions is the settings of the Elasticsearch sink. It also works with OpenSearch.
+type ElasticsearchOptions struct {
+	// URL is the base URL of the cluster. Default is "http://localhost:9200".
+	URL string
+	// Index is the index name formatted with the time of the entry in UTC by time.Format.
+	// e.g. "app-2006.01.02" is "app-2026.10.17". Default is "zl-2006.01.02".
+	// Note that the parts of the name that match the layout of time.Format are also replaced.
+	Index string
+	// Username and Password are used for the basic authentication.
+	Username string
+	Password string
+	// APIKey is the encoded API key set to the Authorization header.
+	APIKey string
+	// Headers are added to the requests.
+	Headers map[string]string
+	// Timeout is the timeout of a request. Default is 10 seconds.
+	Timeout time.Duration
+	// Batch is the settings of batching.
+	Batch BatchOptions
+}
+
+// ElasticsearchWriter is a zapcore.WriteSyncer that sends the entries encoded by JSONEncoder
+// to Elasticsearch or OpenSearch with the _bulk API.
+// The entries rejected with 429 Too Many Requests or 5xx are retried with backoff,
+// and the other rejected entries are dropped with an error.
+// See: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-bulk.html
+//
+// A typical usage would be something like.
+//
+//	w := zl.NewElasticsearchWriter(zl.ElasticsearchOptions{URL: "http://localhost:9200", Index: "app-2006.01.02"})
+//	zl.AddSink("elasticsearch", w, zl.SinkOptions{Level: zl.InfoLevel})
+//	zl.Init()
+//	defer w.Close()
+type ElasticsearchWriter struct {
+	*batchWriter
+	options ElasticsearchOptions
+	client  *http.Client
+}
+
+// NewElasticsearchWriter returns the ElasticsearchWriter.
+func NewElasticsearchWriter(options ElasticsearchOptions) *ElasticsearchWriter {
+	if options.URL == "" {
+		options.URL = "http://localhost:9200"
+	}
+	if options.Index == "" {
+		options.Index = "zl-2006.01.02"
+	}
+	if options.Timeout == 0 {
+		options.Timeout = 10 * time.Second
+	}
+	w := &ElasticsearchWriter{options: options, client: &http.Client{Timeout: options.Timeout}}
+	w.batchWriter = newBatchWriter(options.Batch, dropNonJSON(w.send))
+	return w
+}
+
+// requiresJSON implements jsonSink.
+func (w *ElasticsearchWriter) requiresJSON() {}
+
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+func (w *ElasticsearchWriter) send(entries [][]byte) error {
+	var body bytes.Buffer
+	var sent [][]byte
+	for _, p := range entries {
+		m, err := parseEntry(p)
+		if err != nil {
+			continue
+		}
+		index := entryTime(m).UTC().Format(w.options.Index)
+		fmt.Fprintf(&body, `{"create":{"_index":%q}}`+"\n", index)
+		body.Write(bytes.TrimRight(p, "\n"))
+		body.WriteByte('\n')
+		sent = append(sent, p)
+	}
+	if len(sent) == 0 {
+		return nil
+	}
+
+	url := strings.TrimRight(w.options.URL, "/") + "/_bulk"
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, &body)
+	if err != nil {
+		return &permanentError{err: err}
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if w.options.Username != "" {
+		req.SetBasicAuth(w.options.Username, w.options.Password)
+	}
+	if w.options.APIKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+w.options.APIKey)
+	}
+	for k, v := range w.options.Headers {
+		req.Header.Set(k, v)
+	}
+	b, err := doRequest(w.client, req)
+	if err != nil {
+		return err
+	}
+
+	var resp bulkResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return &permanentError{err: fmt.Errorf("failed to parse the bulk response: %w", err)}
+	}
+	if !resp.Errors {
+		return nil
+	}
+	return bulkError(sent, &resp)
+}
+
+// bulkError returns the error of the items failed in the bulk request.
+// The entries rejected with a retryable status are retried, and the others are dropped.
+func bulkError(sent [][]byte, resp *bulkResponse) error {
+	var retry [][]byte
+	var rejected int
+	var reason json.RawMessage
+	for i, item := range resp.Items {
+		for _, result := range item {
+			switch {
+			case result.Status < http.StatusMultipleChoices:
+			case isRetryableStatus(result.Status) && i < len(sent):
+				retry = append(retry, sent[i])
+			default:
+				rejected++
+				if reason == nil {
+					reason = result.Error
+				}
+			}
+		}
+	}
+	var dropped error
+	if rejected > 0 {
+		dropped = fmt.Errorf("%d entries are rejected by the bulk request: %s", rejected, reason)
+	}
+	return &partialError{
+		entries: retry,
+		dropped: dropped,
+		err:     fmt.Errorf("%d entries are not indexed by the bulk request", len(retry)),
+	}
+}
@@ -0,0 +1,124 @@
+package zl
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bulkRequest struct {
+	header http.Header
+	lines  []string
+}
+
+func newBulkServer(t *testing.T, responses ...func(w http.ResponseWriter)) (*httptest.Server, <-chan bulkRequest) {
+	requests := make(chan bulkRequest, 10)
+	i := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_bulk", r.URL.Path)
+		req := bulkRequest{header: r.Header}
+		s := bufio.NewScanner(r.Body)
+		for s.Scan() {
+			req.lines = append(req.lines, s.Text())
+		}
+		requests <- req
+		if i < len(responses) {
+			responses[i](w)
+		} else {
+			_, _ = w.Write([]byte(`{"errors":false,"items":[]}`))
+		}
+		i++
+	}))
+	return server, requests
+}
+
+func TestElasticsearchWriter(t *testing.T) {
+	server, requests := newBulkServer(t)
+	defer server.Close()
+
+	w := NewElasticsearchWriter(ElasticsearchOptions{
+		URL:      server.URL + "/",
+		Index:    "app-2006.01.02",
+		Username: "user",
+		Password: "pass",
+		Batch:    BatchOptions{Interval: time.Hour},
+	})
+	defer w.Close()
+
+	for _, entry := range []string{
+		`{"severity":"INFO","timestamp":"2026-10-17T23:59:59+09:00","message":"FIRST"}` + "\n",
+		`{"severity":"INFO","timestamp":"2026-10-18T00:00:00Z","message":"SECOND"}` + "\n",
+		`INVALID`,
+	} {
+		_, err := w.Write([]byte(entry))
+		assert.NoError(t, err)
+	}
+	assert.ErrorContains(t, w.Sync(), "1 entries are dropped as they are not encoded by JSONEncoder")
+
+	req := <-requests
+	assert.Equal(t, "application/x-ndjson", req.header.Get("Content-Type"))
+	user, pass, ok := (&http.Request{Header: req.header}).BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "user", user)
+	assert.Equal(t, "pass", pass)
+	assert.Equal(t, []string{
+		`{"create":{"_index":"app-2026.10.17"}}`,
+		`{"severity":"INFO","timestamp":"2026-10-17T23:59:59+09:00","message":"FIRST"}`,
+		`{"create":{"_index":"app-2026.10.18"}}`,
+		`{"severity":"INFO","timestamp":"2026-10-18T00:00:00Z","message":"SECOND"}`,
+	}, req.lines)
+}
+
+func TestElasticsearchWriter_partialFailure(t *testing.T) {
+	server, requests := newBulkServer(t,
+		func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		},
+		func(w http.ResponseWriter) {
+			_, _ = w.Write([]byte(`{"errors":true,"items":[
+				{"create":{"status":201}},
+				{"create":{"status":429,"error":{"type":"es_rejected_execution_exception"}}},
+				{"create":{"status":400,"error":{"type":"mapper_parsing_exception"}}}
+			]}`))
+		},
+	)
+	defer server.Close()
+
+	w := NewElasticsearchWriter(ElasticsearchOptions{
+		URL:    server.URL,
+		APIKey: "key",
+		Batch:  BatchOptions{Interval: time.Hour, RetryBackoff: time.Millisecond},
+	})
+	defer w.Close()
+
+	for _, msg := range []string{"FIRST", "SECOND", "THIRD"} {
+		_, err := w.Write([]byte(`{"message":"` + msg + `"}`))
+		assert.NoError(t, err)
+	}
+	// the entries rejected with 429 are sent again at the next Sync.
+	assert.ErrorContains(t, w.Sync(), "429")
+	err := w.Sync()
+	assert.ErrorContains(t, err, "1 entries are rejected by the bulk request")
+	assert.ErrorContains(t, err, "mapper_parsing_exception")
+	assert.NoError(t, w.Sync())
+
+	// 429 for the request.
+	req := <-requests
+	assert.Equal(t, "ApiKey key", req.header.Get("Authorization"))
+	assert.Len(t, req.lines, 6)
+	// partial failure.
+	req = <-requests
+	assert.Len(t, req.lines, 6)
+	// only the entry rejected with 429 is retried.
+	req = <-requests
+	assert.Len(t, req.lines, 2)
+	var doc map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(req.lines[1]), &doc))
+	assert.Equal(t, "SECOND", doc["message"])
+	assert.Empty(t, requests)
+}
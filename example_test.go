@@ -89,7 +89,7 @@ func Example() {
 	// os.Exit(1) called.
 	//
 	// log file output:
-	// {"severity":"DEBUG","function":"github.com/nkmr-jp/zl.Init.func1","message":"INIT_LOGGER","console":"Severity: DEBUG, Output: Pretty, File: ./log/example.jsonl"}
+	// {"severity":"DEBUG","function":"github.com/nkmr-jp/zl.Init","message":"INIT_LOGGER","console":"Severity: DEBUG, Output: Pretty, File: ./log/example.jsonl"}
 	// {"severity":"INFO","function":"github.com/nkmr-jp/zl_test.Example","message":"USER_INFO","user_name":"Alice","user_age":20}
 	// {"severity":"INFO","function":"github.com/nkmr-jp/zl_test.Example","message":"DISPLAY_TO_CONSOLE","console":"display to console when output type is pretty"}
 	// {"severity":"INFO","function":"github.com/nkmr-jp/zl_test.Example","message":"DISPLAY_TO_CONSOLE","console":"display to console when output type is pretty"}
@@ -139,7 +139,7 @@ func ExampleSetVersion() {
 	fmt.Println(string(bytes))
 
 	// Output:
-	// {"severity":"DEBUG","caller":"zl/zl.go:86","message":"INIT_LOGGER","version":"v1.0.0","console":"Severity: DEBUG, Output: ConsoleAndFile, File: ./log/example-set-version_v1.0.0.jsonl"}
+	// {"severity":"DEBUG","caller":"zl/zl.go:57","message":"INIT_LOGGER","version":"v1.0.0","console":"Severity: DEBUG, Output: ConsoleAndFile, File: ./log/example-set-version_v1.0.0.jsonl"}
 	// {"severity":"INFO","caller":"https://github.com/nkmr-jp/zl/blob/v1.0.0/example_test.go#L135","message":"INFO_MESSAGE","version":"v1.0.0","detail":"detail info xxxxxxxxxxxxxxxxx"}
 	// {"severity":"WARN","caller":"https://github.com/nkmr-jp/zl/blob/v1.0.0/example_test.go#L136","message":"WARN_MESSAGE","version":"v1.0.0","detail":"detail info xxxxxxxxxxxxxxxxx"}
 
@@ -220,20 +220,20 @@ func ExampleNew() {
 	// log file output:
 	// {"severity":"DEBUG","message":"INIT_LOGGER","console":"Severity: DEBUG, Output: Pretty, File: ./log/example-new.jsonl"}
 	// {"severity":"INFO","message":"GLOBAL_INFO"}
-	// {"severity":"INFO","message":"CONTEXT_SCOPE_INFO","console":"some message to console: test","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50"}
-	// {"severity":"ERROR","message":"CONTEXT_SCOPE_ERROR","error":"context scope error message","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50"}
-	// {"severity":"INFO","logger":"named1","message":"CONTEXT_SCOPE_INFO2","console":"some message to console: test","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50"}
+	// {"severity":"INFO","message":"CONTEXT_SCOPE_INFO","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50","console":"some message to console: test"}
+	// {"severity":"ERROR","message":"CONTEXT_SCOPE_ERROR","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50","error":"context scope error message"}
+	// {"severity":"INFO","logger":"named1","message":"CONTEXT_SCOPE_INFO2","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50","console":"some message to console: test"}
 	// {"severity":"DEBUG","logger":"named2","message":"TEST","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50"}
 	// {"severity":"WARN","logger":"named1.named3","message":"TEST","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50"}
 	// {"severity":"ERROR","message":"TEST","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50"}
-	// {"severity":"ERROR","logger":"named1","message":"TEST","error":"error","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50"}
-	// {"severity":"ERROR","logger":"named2","message":"TEST","error":"error","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50"}
-	// {"severity":"ERROR","logger":"named1.named3","message":"TEST","error":"error","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50"}
-	// {"severity":"INFO","message":"TEST","error":"error","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50"}
-	// {"severity":"DEBUG","logger":"named1","message":"TEST","error":"error","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50"}
-	// {"severity":"WARN","logger":"named2","message":"TEST","error":"error","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50"}
+	// {"severity":"ERROR","logger":"named1","message":"TEST","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50","error":"error"}
+	// {"severity":"ERROR","logger":"named2","message":"TEST","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50","error":"error"}
+	// {"severity":"ERROR","logger":"named1.named3","message":"TEST","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50","error":"error"}
+	// {"severity":"INFO","message":"TEST","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50","error":"error"}
+	// {"severity":"DEBUG","logger":"named1","message":"TEST","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50","error":"error"}
+	// {"severity":"WARN","logger":"named2","message":"TEST","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50","error":"error"}
 	// {"severity":"FATAL","logger":"named1.named3","message":"TEST","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50"}
-	// {"severity":"FATAL","message":"TEST","error":"error","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50"}
+	// {"severity":"FATAL","message":"TEST","user_id":1,"trace":"c7mg6hnr2g4l6vvuao50","error":"error"}
 }
 
 func ExampleSetLevelByString() {
@@ -338,7 +338,6 @@ func ExampleSyncWhenStop() {
 	time.Sleep(time.Millisecond * 100)
 
 	// syscall.SIGTERM
-	fmt.Println()
 	setupForExampleTest()
 	zl.SetLevel(zl.DebugLevel)
 	zl.SetRotateFileName("./log/example-SyncWhenStop.jsonl")
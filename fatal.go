@@ -0,0 +1,108 @@
+package zl
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// FatalBehavior is the behavior after a FATAL log is written.
+type FatalBehavior struct {
+	// ExitCode is the exit code. Default is 1.
+	ExitCode int
+	// ExitFunc is called with the exit code instead of os.Exit. e.g. to test Fatal.
+	ExitFunc func(code int)
+	// Panic panics with the message instead of exiting after the shutdown functions run,
+	// so deferred functions run and it can be recovered.
+	Panic bool
+}
+
+var (
+	fatalBehavior FatalBehavior
+	shutdownFuncs []func() error
+)
+
+// SetFatalBehavior sets the behavior after a FATAL log is written.
+// By default, the process exits with os.Exit(1) after the shutdown functions run.
+func SetFatalBehavior(behavior FatalBehavior) {
+	mu.Lock()
+	defer mu.Unlock()
+	fatalBehavior = behavior
+}
+
+// AddShutdownFunc adds a function that runs before the process exits by Fatal or SyncWhenStop,
+// or before Fatal panics with FatalBehavior.Panic.
+// e.g. closing the sinks or flushing the metrics.
+// The functions run in the reverse order of addition, like defer.
+func AddShutdownFunc(fn func() error) {
+	mu.Lock()
+	defer mu.Unlock()
+	shutdownFuncs = append(shutdownFuncs, fn)
+}
+
+type fatalHook struct{}
+
+func (f fatalHook) OnWrite(ce *zapcore.CheckedEntry, _ []zapcore.Field) {
+	s := loadState()
+	s.syncBuffers()
+	if s.pretty != nil {
+		s.pretty.showErrorReport(s.reportFileName, s.pid)
+	}
+	behavior, funcs := getFatalBehavior()
+	if behavior.Panic {
+		runShutdownFuncs(funcs)
+		panic(ce.Message)
+	}
+	code := behavior.ExitCode
+	if code == 0 {
+		code = 1
+	}
+	exit(code)
+}
+
+// panicHook writes the buffered logs before panicking by Panic, or by DPanic in the development mode,
+// so that the entry is not lost even if the panic is not recovered.
+type panicHook struct{}
+
+func (p panicHook) OnWrite(ce *zapcore.CheckedEntry, _ []zapcore.Field) {
+	loadState().syncBuffers()
+	panic(ce.Message)
+}
+
+// exit runs the shutdown functions and exits with the code.
+func exit(code int) {
+	behavior, funcs := getFatalBehavior()
+	runShutdownFuncs(funcs)
+	if behavior.ExitFunc != nil {
+		behavior.ExitFunc(code)
+		return
+	}
+	os.Exit(code)
+}
+
+// runShutdownFuncs runs the functions added by AddShutdownFunc in the reverse order.
+func runShutdownFuncs(funcs []func() error) {
+	for i := len(funcs) - 1; i >= 0; i-- {
+		if err := funcs[i](); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// getFatalBehavior returns the FatalBehavior and the shutdown functions.
+// They are called without holding the lock, as they may log or change the settings.
+func getFatalBehavior() (FatalBehavior, []func() error) {
+	mu.Lock()
+	defer mu.Unlock()
+	return fatalBehavior, shutdownFuncs
+}
+
+// SetIsTest prints "os.Exit(code) called." instead of exiting.
+// Deprecated: Use SetFatalBehavior with ExitFunc instead.
+func SetIsTest() {
+	SetFatalBehavior(FatalBehavior{ExitFunc: func(code int) {
+		fmt.Printf("os.Exit(%d) called.\n", code)
+	}})
+}
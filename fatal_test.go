@@ -0,0 +1,69 @@
+package zl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetFatalBehavior(t *testing.T) {
+	for _, output := range []Output{PrettyOutput, ConsoleAndFileOutput, ConsoleOutput, FileOutput} {
+		t.Run(output.String(), func(t *testing.T) {
+			var calls []string
+			ResetGlobalLoggerSettings()
+			SetOutput(output)
+			SetRotateFileName(t.TempDir() + "/app.jsonl")
+			SetFatalBehavior(FatalBehavior{ExitCode: 3, ExitFunc: func(code int) {
+				calls = append(calls, "exit", string(rune('0'+code)))
+			}})
+			AddShutdownFunc(func() error {
+				calls = append(calls, "first")
+				return nil
+			})
+			AddShutdownFunc(func() error {
+				calls = append(calls, "second")
+				return errors.New("some error")
+			})
+			defer ResetGlobalLoggerSettings()
+			Init()
+
+			Fatal("FATAL_MESSAGE")
+			assert.Equal(t, []string{"second", "first", "exit", "3"}, calls)
+
+			calls = nil
+			New().FatalErr("FATAL_MESSAGE", errors.New("some error"))
+			assert.Equal(t, []string{"second", "first", "exit", "3"}, calls)
+		})
+	}
+}
+
+func TestSetFatalBehavior_panic(t *testing.T) {
+	var calls []string
+	fileName := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetRotateFileName(fileName)
+	SetBufferedWrite(0, time.Hour)
+	SetFatalBehavior(FatalBehavior{Panic: true})
+	AddShutdownFunc(func() error {
+		calls = append(calls, "first")
+		return nil
+	})
+	AddShutdownFunc(func() error {
+		calls = append(calls, "second")
+		return nil
+	})
+	defer ResetGlobalLoggerSettings()
+	Init()
+
+	assert.PanicsWithValue(t, "FATAL_MESSAGE", func() {
+		Fatal("FATAL_MESSAGE")
+	})
+	assert.Equal(t, []string{"second", "first"}, calls)
+	b, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "FATAL_MESSAGE")
+}
@@ -0,0 +1,124 @@
+package zl
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"time"
+)
+
+// FluentOptions is the settings of the Fluent Forward sink.
+type FluentOptions struct {
+	// Network is "tcp" or "unix".
+	Network string
+	// Address is "host:port" or the path of the unix socket. e.g. "localhost:24224"
+	Address string
+	// Tag is the tag of the events. Default is the name set by SetServiceName or the name of the executable.
+	Tag string
+	// RequireAck waits for the acknowledgment of each request from the server,
+	// and resends the request when it is not received.
+	RequireAck bool
+	// AckTimeout is the timeout for the acknowledgment. Default is 10 seconds.
+	AckTimeout time.Duration
+	// DialTimeout is the timeout for connecting and for each write. Default is 5 seconds.
+	DialTimeout time.Duration
+	// Batch is the settings of batching. The entries are kept while the server is unavailable, up to Batch.QueueSize.
+	Batch BatchOptions
+}
+
+// FluentWriter is a zapcore.WriteSyncer that sends the entries encoded by JSONEncoder
+// to Fluentd or Fluent Bit with the Forward protocol in PackedForward mode.
+// It connects lazily and reconnects when sending fails.
+// See: https://github.com/fluent/fluentd/wiki/Forward-Protocol-Specification-v1.5
+//
+// A typical usage would be something like.
+//
+//	w := zl.NewFluentWriter(zl.FluentOptions{Network: "tcp", Address: "localhost:24224", Tag: "app.api"})
+//	zl.AddSink("fluent", w, zl.SinkOptions{Level: zl.InfoLevel})
+//	zl.Init()
+//	defer w.Close()
+type FluentWriter struct {
+	*batchWriter
+	options FluentOptions
+	conn    *netConn
+}
+
+// NewFluentWriter returns the FluentWriter.
+func NewFluentWriter(options FluentOptions) *FluentWriter {
+	if options.Tag == "" {
+		options.Tag = appName()
+	}
+	if options.AckTimeout == 0 {
+		options.AckTimeout = 10 * time.Second
+	}
+	if options.DialTimeout == 0 {
+		options.DialTimeout = 5 * time.Second
+	}
+	w := &FluentWriter{
+		options: options,
+		conn:    &netConn{network: options.Network, address: options.Address, timeout: options.DialTimeout},
+	}
+	w.batchWriter = newBatchWriter(options.Batch, dropNonJSON(w.send))
+	return w
+}
+
+// requiresJSON implements jsonSink.
+func (w *FluentWriter) requiresJSON() {}
+
+// Close sends the queued entries and closes the connection.
+func (w *FluentWriter) Close() error {
+	if err := w.batchWriter.Close(); err != nil {
+		return err
+	}
+	return w.conn.close()
+}
+
+func (w *FluentWriter) send(entries [][]byte) error {
+	var events []byte
+	size := 0
+	for _, p := range entries {
+		m, err := parseEntry(p)
+		if err != nil {
+			continue
+		}
+		events = appendMsgpack(events, []interface{}{msgpackEventTime(entryTime(m)), m})
+		size++
+	}
+	if size == 0 {
+		return nil
+	}
+
+	option := map[string]interface{}{"size": size}
+	var chunk string
+	if w.options.RequireAck {
+		id := make([]byte, 16)
+		if _, err := rand.Read(id); err != nil {
+			return err
+		}
+		chunk = base64.StdEncoding.EncodeToString(id)
+		option["chunk"] = chunk
+	}
+	msg := appendMsgpack(nil, []interface{}{w.options.Tag, events, option})
+
+	return w.conn.do(func(conn net.Conn) error {
+		if _, err := conn.Write(msg); err != nil {
+			return err
+		}
+		if chunk == "" {
+			return nil
+		}
+		if err := conn.SetReadDeadline(time.Now().Add(w.options.AckTimeout)); err != nil {
+			return err
+		}
+		resp, err := readMsgpack(bufio.NewReader(conn))
+		if err != nil {
+			return fmt.Errorf("failed to read the ack: %w", err)
+		}
+		if m, ok := resp.(map[string]interface{}); !ok || m["ack"] != chunk {
+			return fmt.Errorf("unexpected ack: %v", resp)
+		}
+		return conn.SetReadDeadline(time.Time{})
+	})
+}
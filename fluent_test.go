@@ -0,0 +1,195 @@
+package zl
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// forwardMessage is a message received by the fake server.
+type forwardMessage struct {
+	tag    string
+	events [][]interface{}
+	option map[string]interface{}
+}
+
+// serveForward accepts the connections and reads the messages in PackedForward mode.
+// It responds to the chunk option when ack is true.
+func serveForward(t *testing.T, ln net.Listener, ack bool) <-chan forwardMessage {
+	received := make(chan forwardMessage, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					v, err := readMsgpack(r)
+					if err != nil {
+						return
+					}
+					msg := v.([]interface{})
+					fm := forwardMessage{tag: msg[0].(string), option: msg[2].(map[string]interface{})}
+					er := bufio.NewReader(bytes.NewReader(msg[1].([]byte)))
+					for {
+						e, err := readMsgpack(er)
+						if err != nil {
+							break
+						}
+						fm.events = append(fm.events, e.([]interface{}))
+					}
+					if ack {
+						if _, err := conn.Write(appendMsgpack(nil, map[string]interface{}{"ack": fm.option["chunk"]})); err != nil {
+							t.Error(err)
+						}
+					}
+					received <- fm
+				}
+			}()
+		}
+	}()
+	return received
+}
+
+// closingListener closes the accepted connections as well when it is closed, to stop the fake server.
+type closingListener struct {
+	net.Listener
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func (l *closingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.mu.Lock()
+		l.conns = append(l.conns, conn)
+		l.mu.Unlock()
+	}
+	return conn, err
+}
+
+func (l *closingListener) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, c := range l.conns {
+		c.Close()
+	}
+	return l.Listener.Close()
+}
+
+func TestFluentWriter(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	received := serveForward(t, ln, true)
+
+	w := NewFluentWriter(FluentOptions{
+		Network:    "tcp",
+		Address:    ln.Addr().String(),
+		Tag:        "app.test",
+		RequireAck: true,
+		Batch:      BatchOptions{Interval: time.Hour},
+	})
+	defer w.Close()
+
+	for _, entry := range []string{
+		`{"severity":"INFO","timestamp":"2026-10-17T12:00:00.5Z","message":"FIRST","count":1}`,
+		`{"severity":"WARN","message":"SECOND"}`,
+	} {
+		_, err := w.Write([]byte(entry))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Sync())
+
+	msg := <-received
+	assert.Equal(t, "app.test", msg.tag)
+	assert.Equal(t, int64(2), msg.option["size"])
+	assert.NotEmpty(t, msg.option["chunk"])
+	assert.Len(t, msg.events, 2)
+	assert.Equal(t, []byte{0x00, 0x6a, 0xd3, 0x63, 0x40, 0x1d, 0xcd, 0x65, 0x00}, msg.events[0][0])
+	assert.Equal(t, map[string]interface{}{
+		"severity":  "INFO",
+		"timestamp": "2026-10-17T12:00:00.5Z",
+		"message":   "FIRST",
+		"count":     int64(1),
+	}, msg.events[0][1])
+	assert.Equal(t, "SECOND", msg.events[1][1].(map[string]interface{})["message"])
+}
+
+func TestFluentWriter_outage(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "fluent.sock")
+	w := NewFluentWriter(FluentOptions{
+		Network: "unix",
+		Address: addr,
+		Batch:   BatchOptions{Size: 1, Interval: time.Hour, MaxRetries: 20, RetryBackoff: 10 * time.Millisecond},
+	})
+	defer w.Close()
+
+	// the full batch is retried until the server starts.
+	_, err := w.Write([]byte(`{"message":"BEFORE_START"}`))
+	assert.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	ln, err := net.Listen("unix", addr)
+	assert.NoError(t, err)
+	defer ln.Close()
+	received := serveForward(t, ln, false)
+
+	select {
+	case msg := <-received:
+		assert.Len(t, msg.events, 1)
+		assert.Equal(t, "BEFORE_START", msg.events[0][1].(map[string]interface{})["message"])
+		assert.NotContains(t, msg.option, "chunk")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the entry is not sent after the server started")
+	}
+}
+
+func TestFluentWriter_restart(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "fluent.sock")
+	listen := func() (*closingListener, <-chan forwardMessage) {
+		ln, err := net.Listen("unix", addr)
+		assert.NoError(t, err)
+		l := &closingListener{Listener: ln}
+		t.Cleanup(func() { l.Close() })
+		return l, serveForward(t, l, true)
+	}
+	ln, received := listen()
+	w := NewFluentWriter(FluentOptions{
+		Network:    "unix",
+		Address:    addr,
+		RequireAck: true,
+		AckTimeout: 100 * time.Millisecond,
+		Batch:      BatchOptions{Interval: time.Hour, MaxRetries: 2, RetryBackoff: 10 * time.Millisecond},
+	})
+	defer w.Close()
+
+	_, err := w.Write([]byte(`{"message":"BEFORE_STOP"}`))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Sync())
+	msg := <-received
+	assert.Equal(t, "BEFORE_STOP", msg.events[0][1].(map[string]interface{})["message"])
+
+	// the server is stopped longer than the retry window.
+	assert.NoError(t, ln.Close())
+	_, err = w.Write([]byte(`{"message":"WHILE_STOPPED"}`))
+	assert.NoError(t, err)
+	assert.ErrorContains(t, w.Sync(), "they are sent again with the next batch")
+
+	_, received = listen()
+	_, err = w.Write([]byte(`{"message":"AFTER_RESTART"}`))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Sync())
+	msg = <-received
+	assert.Len(t, msg.events, 2)
+	assert.Equal(t, "WHILE_STOPPED", msg.events[0][1].(map[string]interface{})["message"])
+	assert.Equal(t, "AFTER_RESTART", msg.events[1][1].(map[string]interface{})["message"])
+}
@@ -0,0 +1,200 @@
+package zl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+const (
+	gelfChunkSizeDefault = 1420
+	gelfMaxChunks        = 128
+	gelfChunkHeaderSize  = 12
+)
+
+var (
+	gelfChunkMagic = []byte{0x1e, 0x0f}
+	gelfFieldName  = regexp.MustCompile(`[^\w.\-]`)
+)
+
+// GELFOptions is the settings of the GELF sink.
+type GELFOptions struct {
+	// Network is "udp" or "tcp".
+	Network string
+	// Address is "host:port". e.g. "localhost:12201"
+	Address string
+	// Compress compresses the messages with gzip. It is available only for UDP.
+	Compress bool
+	// ChunkSize is the maximum size of a UDP datagram. Default is 1420 bytes.
+	ChunkSize int
+	// Hostname is the host field. Default is the HostnameKey field of the entry or os.Hostname.
+	Hostname string
+	// DialTimeout is the timeout for connecting and for each write. Default is 5 seconds.
+	DialTimeout time.Duration
+}
+
+// GELFWriter is a zapcore.WriteSyncer that sends the entries encoded by JSONEncoder
+// to Graylog in GELF 1.1 format. The fields other than the message, level, time and hostname
+// are sent as additional fields prefixed with "_".
+// UDP messages larger than ChunkSize are chunked, and TCP messages are terminated with a null byte.
+// See: https://go2docs.graylog.org/current/getting_in_log_data/gelf.html
+//
+// A typical usage would be something like.
+//
+//	w, err := zl.NewGELFWriter(zl.GELFOptions{Network: "udp", Address: "localhost:12201", Compress: true})
+//	if err != nil {
+//	  log.Fatal(err)
+//	}
+//	zl.AddSink("graylog", w, zl.SinkOptions{Level: zl.InfoLevel})
+type GELFWriter struct {
+	options  GELFOptions
+	conn     *netConn
+	hostname string
+}
+
+// NewGELFWriter connects to the Graylog server and returns the GELFWriter.
+func NewGELFWriter(options GELFOptions) (*GELFWriter, error) {
+	if options.ChunkSize <= gelfChunkHeaderSize {
+		options.ChunkSize = gelfChunkSizeDefault
+	}
+	if options.DialTimeout == 0 {
+		options.DialTimeout = 5 * time.Second
+	}
+	conn, err := dialNetConn(options.Network, options.Address, options.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	w := &GELFWriter{options: options, conn: conn}
+	if h := getHost(); h != nil {
+		w.hostname = *h
+	}
+	return w, nil
+}
+
+// requiresJSON implements jsonSink.
+func (w *GELFWriter) requiresJSON() {}
+
+// Write implements io.Writer. p is an entry encoded by JSONEncoder.
+func (w *GELFWriter) Write(p []byte) (int, error) {
+	m, err := parseEntry(p)
+	if err != nil {
+		return 0, err
+	}
+	msg, err := json.Marshal(w.format(m))
+	if err != nil {
+		return 0, err
+	}
+
+	if w.conn.isStream() {
+		err = w.conn.write(append(msg, 0))
+	} else {
+		err = w.writePacket(msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *GELFWriter) writePacket(msg []byte) error {
+	if w.options.Compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(msg); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		msg = buf.Bytes()
+	}
+	if len(msg) <= w.options.ChunkSize {
+		return w.conn.write(msg)
+	}
+	chunks, err := gelfChunks(msg, w.options.ChunkSize)
+	if err != nil {
+		return err
+	}
+	return w.conn.write(chunks...)
+}
+
+// Sync implements zapcore.WriteSyncer.
+func (w *GELFWriter) Sync() error {
+	return nil
+}
+
+// Close closes the connection.
+func (w *GELFWriter) Close() error {
+	return w.conn.close()
+}
+
+// format returns the GELF message.
+func (w *GELFWriter) format(m map[string]interface{}) map[string]interface{} {
+	host := w.options.Hostname
+	if host == "" {
+		host = entryString(m, HostnameKey)
+	}
+	if host == "" {
+		host = w.hostname
+	}
+	msg := map[string]interface{}{
+		"version":       "1.1",
+		"host":          host,
+		"short_message": entryString(m, MessageKey),
+		"timestamp":     float64(entryTime(m).UnixMilli()) / 1000,
+		"level":         syslogSeverity(entryLevel(m)),
+	}
+	if stack := entryString(m, StacktraceKey); stack != "" {
+		msg["full_message"] = stack
+	}
+	for k, v := range m {
+		switch k {
+		case fieldKey(MessageKey), fieldKey(LevelKey), fieldKey(TimeKey),
+			fieldKey(HostnameKey), fieldKey(StacktraceKey):
+			continue
+		}
+		name := "_" + gelfFieldName.ReplaceAllString(k, "_")
+		if name == "_id" {
+			name = "__id"
+		}
+		switch v := v.(type) {
+		case string, json.Number:
+			msg[name] = v
+		default:
+			msg[name] = logfmtValue(v)
+		}
+	}
+	return msg
+}
+
+// gelfChunks splits the message into the chunks.
+// Each chunk has the magic bytes, the message id, the sequence number and the sequence count.
+func gelfChunks(msg []byte, size int) ([][]byte, error) {
+	dataSize := size - gelfChunkHeaderSize
+	count := (len(msg) + dataSize - 1) / dataSize
+	if count > gelfMaxChunks {
+		return nil, fmt.Errorf("the GELF message is too large. it needs %d chunks (max %d)", count, gelfMaxChunks)
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * dataSize
+		if end > len(msg) {
+			end = len(msg)
+		}
+		chunk := make([]byte, 0, gelfChunkHeaderSize+end-i*dataSize)
+		chunk = append(chunk, gelfChunkMagic...)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, msg[i*dataSize:end]...)
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
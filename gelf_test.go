@@ -0,0 +1,162 @@
+package zl
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGELFWriter_udp(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	w, err := NewGELFWriter(GELFOptions{Network: "udp", Address: conn.LocalAddr().String()})
+	assert.NoError(t, err)
+	defer w.Close()
+
+	entry := `{"severity":"ERROR","timestamp":"2026-10-17T12:00:00.123456789Z","message":"SOME_ERROR",` +
+		`"hostname":"host1","pid":123,"id":"abc","user name":"Alice","ok":true,"stacktrace":"main.main"}` + "\n"
+	n, err := w.Write([]byte(entry))
+	assert.NoError(t, err)
+	assert.Equal(t, len(entry), n)
+	assert.NoError(t, w.Sync())
+
+	buf := make([]byte, 2048)
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err = conn.ReadFrom(buf)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"version": "1.1",
+		"host": "host1",
+		"short_message": "SOME_ERROR",
+		"full_message": "main.main",
+		"timestamp": 1792238400.123,
+		"level": 3,
+		"_pid": 123,
+		"__id": "abc",
+		"_user_name": "Alice",
+		"_ok": "true"
+	}`, string(buf[:n]))
+}
+
+func TestGELFWriter_chunked(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	w, err := NewGELFWriter(GELFOptions{
+		Network:   "udp",
+		Address:   conn.LocalAddr().String(),
+		Compress:  true,
+		ChunkSize: 100,
+		Hostname:  "host2",
+	})
+	assert.NoError(t, err)
+	defer w.Close()
+
+	// the compressed message is larger than a chunk.
+	long := make([]byte, 300)
+	for i := range long {
+		long[i] = byte('a' + i*7919%26)
+	}
+	entry, err := json.Marshal(map[string]string{"severity": "INFO", "message": "LONG", "data": string(long)})
+	assert.NoError(t, err)
+	_, err = w.Write(entry)
+	assert.NoError(t, err)
+
+	var payload []byte
+	var id []byte
+	count := 0
+	for i := 0; count == 0 || i < count; i++ {
+		buf := make([]byte, 2048)
+		assert.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		chunk := buf[:n]
+		assert.LessOrEqual(t, len(chunk), 100)
+		assert.Equal(t, []byte{0x1e, 0x0f}, chunk[:2])
+		if id == nil {
+			id = chunk[2:10]
+		}
+		assert.Equal(t, id, chunk[2:10])
+		assert.Equal(t, byte(i), chunk[10])
+		count = int(chunk[11])
+		payload = append(payload, chunk[12:]...)
+	}
+	assert.Greater(t, count, 1)
+
+	r, err := gzip.NewReader(bytes.NewReader(payload))
+	assert.NoError(t, err)
+	msg, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	var m map[string]interface{}
+	assert.NoError(t, json.Unmarshal(msg, &m))
+	assert.Equal(t, "host2", m["host"])
+	assert.Equal(t, "LONG", m["short_message"])
+	assert.Equal(t, float64(6), m["level"])
+	assert.Equal(t, string(long), m["_data"])
+}
+
+func TestGELFWriter_tcp(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	received := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			msg, err := r.ReadString(0)
+			if err != nil {
+				return
+			}
+			received <- msg
+		}
+	}()
+
+	w, err := NewGELFWriter(GELFOptions{Network: "tcp", Address: ln.Addr().String()})
+	assert.NoError(t, err)
+	defer w.Close()
+
+	for _, msg := range []string{"FIRST", "SECOND"} {
+		_, err = w.Write([]byte(`{"severity":"WARN","message":"` + msg + `"}`))
+		assert.NoError(t, err)
+	}
+	for _, expected := range []string{"FIRST", "SECOND"} {
+		select {
+		case msg := <-received:
+			assert.True(t, strings.HasSuffix(msg, "\x00"))
+			var m map[string]interface{}
+			assert.NoError(t, json.Unmarshal([]byte(strings.TrimSuffix(msg, "\x00")), &m))
+			assert.Equal(t, expected, m["short_message"])
+			assert.Equal(t, float64(4), m["level"])
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+}
+
+func Test_gelfChunks(t *testing.T) {
+	chunks, err := gelfChunks(make([]byte, 25), 22)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 3)
+	assert.Len(t, chunks[2], 17)
+
+	_, err = gelfChunks(make([]byte, 129*10), 22)
+	assert.Error(t, err)
+}
@@ -7,6 +7,8 @@ require (
 	github.com/logrusorgru/aurora/v4 v4.0.0
 	github.com/samber/lo v1.49.1
 	github.com/stretchr/testify v1.10.0
+	go.opentelemetry.io/otel v1.29.0
+	go.opentelemetry.io/otel/trace v1.29.0
 	go.uber.org/zap v1.27.0
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1
 )
@@ -0,0 +1,78 @@
+package zl
+
+import (
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Entry is a log entry passed to the hooks. It is an alias of zapcore.Entry.
+type Entry = zapcore.Entry
+
+// hook is a function added by AddHook.
+type hook struct {
+	fn     func(Entry, []zap.Field) error
+	levels []zapcore.Level
+}
+
+var hooks []*hook
+
+// AddHook adds a function that runs for each entry in all output types.
+// It runs for the entries of the levels, or all levels if the levels are omitted,
+// and only for the levels enabled by SetLevel. The fields include the fields of the logger.
+// An error returned by the function is written to stderr. It must be called before Init.
+//
+// A typical usage would be something like.
+//
+//	zl.AddHook(func(entry zl.Entry, fields []zap.Field) error {
+//	  errorCount.Inc()
+//	  return nil
+//	}, zl.ErrorLevel, zl.FatalLevel)
+func AddHook(fn func(Entry, []zap.Field) error, levels ...zapcore.Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	hooks = append(hooks, &hook{fn: fn, levels: levels})
+}
+
+func (h *hook) enabled(level zapcore.Level) bool {
+	return len(h.levels) == 0 || lo.Contains(h.levels, level)
+}
+
+// hookCore is a zapcore.Core that runs the hook instead of writing the entries.
+type hookCore struct {
+	hook   *hook
+	lowest zapcore.Level // lowest is the level set by SetLevel when the core is built.
+	fields []zap.Field
+}
+
+func (h *hook) newCore() zapcore.Core {
+	return &hookCore{hook: h, lowest: severityLevel}
+}
+
+// Enabled implements zapcore.LevelEnabler.
+func (c *hookCore) Enabled(level zapcore.Level) bool {
+	return level >= c.lowest && c.hook.enabled(level)
+}
+
+// With implements zapcore.Core.
+func (c *hookCore) With(fields []zap.Field) zapcore.Core {
+	return &hookCore{hook: c.hook, lowest: c.lowest, fields: append(c.fields[:len(c.fields):len(c.fields)], fields...)}
+}
+
+// Check implements zapcore.Core.
+func (c *hookCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write implements zapcore.Core.
+func (c *hookCore) Write(ent zapcore.Entry, fields []zap.Field) error {
+	return c.hook.fn(ent, append(c.fields[:len(c.fields):len(c.fields)], fields...))
+}
+
+// Sync implements zapcore.Core.
+func (c *hookCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,53 @@
+package zl
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestAddHook(t *testing.T) {
+	for _, output := range []Output{PrettyOutput, ConsoleAndFileOutput, ConsoleOutput, FileOutput} {
+		t.Run(output.String(), func(t *testing.T) {
+			var all, errs []string
+			var fields map[string]interface{}
+			ResetGlobalLoggerSettings()
+			SetOutput(output)
+			SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+			SetOmitKeys(VersionKey, HostnameKey, PIDKey)
+			AddHook(func(entry Entry, f []zap.Field) error {
+				if entry.Message == "INIT_LOGGER" {
+					return nil
+				}
+				all = append(all, entry.Level.CapitalString()+" "+entry.Message)
+				enc := zapcore.NewMapObjectEncoder()
+				for i := range f {
+					f[i].AddTo(enc)
+				}
+				fields = enc.Fields
+				return nil
+			})
+			AddHook(func(entry Entry, f []zap.Field) error {
+				assert.True(t, entry.Caller.Defined)
+				assert.NotEmpty(t, entry.Stack)
+				errs = append(errs, entry.Message)
+				return nil
+			}, ErrorLevel, FatalLevel)
+			defer ResetGlobalLoggerSettings()
+			Init()
+
+			Debug("DEBUG_MESSAGE")
+			Info("INFO_MESSAGE")
+			Err("ERROR_MESSAGE", errors.New("some error"))
+			New(zap.String("request_id", "1")).Warn("WARN_MESSAGE", zap.Int("count", 1))
+
+			assert.Equal(t, []string{"INFO INFO_MESSAGE", "ERROR ERROR_MESSAGE", "WARN WARN_MESSAGE"}, all)
+			assert.Equal(t, []string{"ERROR_MESSAGE"}, errs)
+			assert.Equal(t, map[string]interface{}{"request_id": "1", "count": int64(1)}, fields)
+		})
+	}
+}
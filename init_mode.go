@@ -0,0 +1,91 @@
+package zl
+
+import (
+	"log"
+	"os"
+)
+
+// InitMode is the behavior when the logger is used before Init is called.
+type InitMode int
+
+const (
+	// ExplicitInit exits with log.Fatal when the logger is used before Init. It is Default setting.
+	ExplicitInit InitMode = iota
+
+	// LazyInit initializes the logger with the settings at the first log, if Init has not been called.
+	// The output type and the level can also be set with the environment variables
+	// ZL_OUTPUT and ZL_LEVEL. e.g. `ZL_OUTPUT=Console ZL_LEVEL=DEBUG`
+	LazyInit
+
+	// NopUntilInit discards the logs until Init is called.
+	// The Loggers created by New before Init start writing after Init.
+	// It is useful for libraries that log before the application initializes the logger.
+	NopUntilInit
+)
+
+// The environment variables read by LazyInit.
+const (
+	outputEnv = "ZL_OUTPUT"
+	levelEnv  = "ZL_LEVEL"
+)
+
+var initMode InitMode
+
+// SetInitMode sets the behavior when the logger is used before Init is called. Default is ExplicitInit.
+//
+// A typical usage would be something like.
+//
+//	func init() {
+//	  // the logs of this package are discarded until the application calls zl.Init.
+//	  zl.SetInitMode(zl.NopUntilInit)
+//	}
+func SetInitMode(mode InitMode) {
+	mu.Lock()
+	defer mu.Unlock()
+	initMode = mode
+}
+
+func checkInit() {
+	if loaded.Load() != nil {
+		return
+	}
+	mu.Lock()
+	mode := initMode
+	mu.Unlock()
+	switch mode {
+	case LazyInit:
+		lazyInit()
+	case NopUntilInit:
+	default:
+		log.Fatal("The logger is not initialized. Init() must be called.")
+	}
+}
+
+// lazyInit initializes the logger with the settings and the environment variables.
+// An invalid environment variable is reported and ignored, so that it does not stop the application.
+func lazyInit() {
+	mu.Lock()
+	if loaded.Load() == nil {
+		setFromEnv()
+	}
+	mu.Unlock()
+	Init()
+}
+
+// setFromEnv sets the output type and the level from the environment variables. mu must be held.
+func setFromEnv() {
+	if v, ok := os.LookupEnv(outputEnv); ok {
+		if output, err := parseOutput(v); err != nil {
+			log.Println(err)
+		} else {
+			outputType = output
+		}
+	}
+	if v, ok := os.LookupEnv(levelEnv); ok {
+		if level, err := parseLevel(v); err != nil {
+			log.Println(err)
+		} else {
+			severityLevel = level
+		}
+	}
+}
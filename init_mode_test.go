@@ -0,0 +1,71 @@
+package zl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestSetInitMode_lazyInit(t *testing.T) {
+	tests := []struct {
+		name     string
+		level    string
+		expected []string
+	}{
+		{"from environment variables", "WARN", []string{`{"severity":"WARN","message":"WARN_MESSAGE"}`}},
+		{"invalid level", "invalid", []string{
+			`{"severity":"INFO","message":"INFO_MESSAGE"}`,
+			`{"severity":"WARN","message":"WARN_MESSAGE"}`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), "app.jsonl")
+			t.Setenv("ZL_OUTPUT", "File")
+			t.Setenv("ZL_LEVEL", tt.level)
+			SetInitMode(LazyInit)
+			SetRotateFileName(fileName)
+			SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, PIDKey)
+			defer ResetGlobalLoggerSettings()
+
+			Info("INFO_MESSAGE")
+			Warn("WARN_MESSAGE")
+			Sync()
+
+			assert.Equal(t, FileOutput, outputType)
+			b, err := os.ReadFile(fileName)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, strings.Split(strings.TrimSpace(string(b)), "\n"))
+		})
+	}
+}
+
+func TestSetInitMode_nopUntilInit(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "app.jsonl")
+	SetInitMode(NopUntilInit)
+	defer ResetGlobalLoggerSettings()
+
+	l := New(zap.String("request_id", "1"))
+	Info("DISCARDED")
+	l.Info("DISCARDED")
+	Infof("DISCARDED %d", 1)
+	Dump("DISCARDED")
+
+	SetOutput(FileOutput)
+	SetRotateFileName(fileName)
+	SetOmitKeys(TimeKey, FunctionKey, VersionKey, HostnameKey, PIDKey)
+	Init()
+	l.Info("WRITTEN")
+	Sync()
+
+	b, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`{"severity":"INFO","caller":"zl/init_mode_test.go:62","message":"WRITTEN","request_id":"1"}`,
+		strings.TrimSpace(string(b)),
+	)
+}
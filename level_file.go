@@ -0,0 +1,122 @@
+package zl
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// FileOptions is the settings of a log file set by SetFileForLevel.
+// Zero values use the settings of the main log file (e.g. SetRotateMaxSize) at Init or Reconfigure.
+type FileOptions struct {
+	// Exclusive writes the entries only to this file instead of also writing them to the main log file.
+	Exclusive    bool
+	MaxSize      int // megabytes
+	MaxBackups   int
+	MaxAge       int   // days
+	MaxTotalSize int   // megabytes
+	Compress     *bool // nil uses SetRotateCompress, and a pointer to false disables it for this file.
+	Interval     RotateInterval
+}
+
+// levelFile is a log file that receives the entries at or above the level.
+type levelFile struct {
+	level   zapcore.Level
+	name    string
+	options FileOptions
+	syncer  rotateSyncer
+	writer  zapcore.WriteSyncer // writer is syncer with buffering if set.
+}
+
+var levelFiles []*levelFile
+
+// SetFileForLevel writes the entries at or above the level to a dedicated rotated file.
+// By default, the entries are written to both the main log file and this file.
+// It is useful for keeping the errors in a small file, and the error report reads from it.
+//
+// e.g. `zl.SetFileForLevel(zl.ErrorLevel, "./log/error.jsonl")`
+func SetFileForLevel(level zapcore.Level, name string, opts ...FileOptions) {
+	mu.Lock()
+	defer mu.Unlock()
+	var opt FileOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	for i := range levelFiles {
+		if levelFiles[i].name == name {
+			levelFiles = append(levelFiles[:i], levelFiles[i+1:]...)
+			break
+		}
+	}
+	levelFiles = append(levelFiles, &levelFile{level: level, name: name, options: opt})
+}
+
+// config returns the rotation settings of the file merged with the settings of the main log file
+// when the loggers are built. mu must be held.
+func (f *levelFile) config() rotateConfig {
+	config := getRotateConfig()
+	config.fileName = f.name
+	config.maxSize = orDefault(f.options.MaxSize, config.maxSize)
+	config.maxBackups = orDefault(f.options.MaxBackups, config.maxBackups)
+	config.maxAge = orDefault(f.options.MaxAge, config.maxAge)
+	config.maxTotalSize = orDefault(f.options.MaxTotalSize, config.maxTotalSize)
+	if f.options.Compress != nil {
+		config.compress = *f.options.Compress
+	}
+	if f.options.Interval != NoInterval {
+		config.interval = f.options.Interval
+	}
+	return config
+}
+
+func orDefault(val, def int) int {
+	if val == 0 {
+		return def
+	}
+	return val
+}
+
+// getSyncer returns the WriteSyncer of the file, creating it when first called.
+func (f *levelFile) getSyncer() zapcore.WriteSyncer {
+	if f.syncer == nil {
+		f.syncer = f.config().newSyncer()
+		f.writer = withBuffer(f.syncer)
+	}
+	return f.writer
+}
+
+// enabler returns the zapcore.LevelEnabler of the file.
+func (f *levelFile) enabler() zapcore.LevelEnabler {
+	lowest := severityLevel
+	return zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+		return level >= f.level && level >= lowest
+	})
+}
+
+// mainFileEnabler returns the zapcore.LevelEnabler of the main log file.
+// It excludes the entries written to exclusive level files.
+func mainFileEnabler() zapcore.LevelEnabler {
+	lowest, excluded := severityLevel, zapcore.InvalidLevel
+	for _, f := range levelFiles {
+		if f.options.Exclusive && (excluded == zapcore.InvalidLevel || f.level < excluded) {
+			excluded = f.level
+		}
+	}
+	return zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+		return level >= lowest && (excluded == zapcore.InvalidLevel || level < excluded)
+	})
+}
+
+// reportFileName returns the file that the error report reads from.
+// It is the smallest level file including all ERROR entries, or the main log file.
+func reportFileName() string {
+	var ret *levelFile
+	for _, f := range levelFiles {
+		if f.level <= ErrorLevel && (ret == nil || f.level > ret.level) {
+			ret = f
+		}
+	}
+	if ret == nil {
+		return fileName
+	}
+	return ret.name
+}
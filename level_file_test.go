@@ -0,0 +1,115 @@
+package zl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetFileForLevel(t *testing.T) {
+	SetRotateMaxSize(10)
+	SetRotateCompress(true)
+	SetFileForLevel(WarnLevel, "./log/warn.jsonl")
+	SetFileForLevel(ErrorLevel, "./log/error.jsonl", FileOptions{Exclusive: true, MaxBackups: 10})
+	SetFileForLevel(ErrorLevel, "./log/error.jsonl", FileOptions{MaxBackups: 5, Interval: Daily})
+	defer ResetGlobalLoggerSettings()
+
+	assert.Len(t, levelFiles, 2)
+	assert.Equal(t, rotateConfig{
+		fileName:   "./log/error.jsonl",
+		maxSize:    10,
+		maxBackups: 5,
+		maxAge:     MaxAgeDefault,
+		compress:   true,
+		interval:   Daily,
+	}, levelFiles[1].config())
+	assert.Equal(t, "./log/error.jsonl", reportFileName())
+}
+
+func TestSetFileForLevel_compress(t *testing.T) {
+	SetRotateCompress(true)
+	SetFileForLevel(WarnLevel, "./log/warn.jsonl")
+	SetFileForLevel(ErrorLevel, "./log/error.jsonl", FileOptions{Compress: lo.ToPtr(false)})
+	defer ResetGlobalLoggerSettings()
+
+	assert.True(t, levelFiles[0].config().compress)
+	assert.False(t, levelFiles[1].config().compress)
+}
+
+func TestSetFileForLevel_settingsAfter(t *testing.T) {
+	SetFileForLevel(ErrorLevel, "./log/error.jsonl")
+	defer ResetGlobalLoggerSettings()
+	assert.Empty(t, fileName)
+
+	SetRotateMaxSize(10)
+	SetRotateMaxBackups(3)
+	assert.Equal(t, rotateConfig{
+		fileName:   "./log/error.jsonl",
+		maxSize:    10,
+		maxBackups: 3,
+		maxAge:     MaxAgeDefault,
+	}, levelFiles[0].config())
+}
+
+func Test_mainFileEnabler(t *testing.T) {
+	SetLevel(DebugLevel)
+	SetFileForLevel(ErrorLevel, "./log/error.jsonl", FileOptions{Exclusive: true})
+	SetFileForLevel(WarnLevel, "./log/warn.jsonl")
+	defer ResetGlobalLoggerSettings()
+
+	enabler := mainFileEnabler()
+	assert.True(t, enabler.Enabled(DebugLevel))
+	assert.True(t, enabler.Enabled(WarnLevel))
+	assert.False(t, enabler.Enabled(ErrorLevel))
+	assert.False(t, enabler.Enabled(FatalLevel))
+
+	enabler = levelFiles[1].enabler()
+	assert.False(t, enabler.Enabled(InfoLevel))
+	assert.True(t, enabler.Enabled(WarnLevel))
+	assert.True(t, enabler.Enabled(ErrorLevel))
+}
+
+func TestSetFileForLevel_write(t *testing.T) {
+	tests := []struct {
+		name          string
+		exclusive     bool
+		expectedInApp bool
+	}{
+		{"in addition to", false, true},
+		{"instead of", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			ResetGlobalLoggerSettings()
+			SetOutput(FileOutput)
+			SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+			SetFileForLevel(ErrorLevel, filepath.Join(dir, "error.jsonl"), FileOptions{Exclusive: tt.exclusive})
+			Init()
+			defer ResetGlobalLoggerSettings()
+
+			Info("INFO_MESSAGE")
+			Err("ERROR_MESSAGE", errors.New("some error"))
+
+			app, err := os.ReadFile(filepath.Join(dir, "app.jsonl"))
+			assert.NoError(t, err)
+			assert.Contains(t, string(app), "INFO_MESSAGE")
+			assert.Equal(t, tt.expectedInApp, strings.Contains(string(app), "ERROR_MESSAGE"))
+
+			errLog, err := os.ReadFile(filepath.Join(dir, "error.jsonl"))
+			assert.NoError(t, err)
+			assert.NotContains(t, string(errLog), "INFO_MESSAGE")
+			assert.Contains(t, string(errLog), "ERROR_MESSAGE")
+
+			assert.NoError(t, Rotate())
+			files, err := filepath.Glob(filepath.Join(dir, "error-*.jsonl"))
+			assert.NoError(t, err)
+			assert.Len(t, files, 1)
+		})
+	}
+}
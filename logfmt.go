@@ -0,0 +1,145 @@
+package zl
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+var logfmtPool = buffer.NewPool()
+
+// logfmtEncoder is a zapcore.Encoder that writes the entries as logfmt.
+// See: https://brandur.org/logfmt
+//
+// The fields added by With are kept in the embedded MapObjectEncoder
+// and written in order of the keys after the entry fields.
+type logfmtEncoder struct {
+	*zapcore.MapObjectEncoder
+	cfg zapcore.EncoderConfig
+}
+
+func newLogfmtEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	return &logfmtEncoder{MapObjectEncoder: zapcore.NewMapObjectEncoder(), cfg: cfg}
+}
+
+// Clone implements zapcore.Encoder.
+func (e *logfmtEncoder) Clone() zapcore.Encoder {
+	clone := zapcore.NewMapObjectEncoder()
+	for k, v := range e.Fields {
+		clone.Fields[k] = v
+	}
+	return &logfmtEncoder{MapObjectEncoder: clone, cfg: e.cfg}
+}
+
+// EncodeEntry implements zapcore.Encoder.
+func (e *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	buf := logfmtPool.Get()
+	if e.cfg.TimeKey != "" && e.cfg.EncodeTime != nil {
+		appendLogfmt(buf, e.cfg.TimeKey, primitive(func(enc zapcore.PrimitiveArrayEncoder) {
+			e.cfg.EncodeTime(ent.Time, enc)
+		}))
+	}
+	if e.cfg.LevelKey != "" {
+		appendLogfmt(buf, e.cfg.LevelKey, ent.Level.CapitalString())
+	}
+	if e.cfg.NameKey != "" && ent.LoggerName != "" {
+		appendLogfmt(buf, e.cfg.NameKey, ent.LoggerName)
+	}
+	if ent.Caller.Defined {
+		if e.cfg.CallerKey != "" && e.cfg.EncodeCaller != nil {
+			appendLogfmt(buf, e.cfg.CallerKey, primitive(func(enc zapcore.PrimitiveArrayEncoder) {
+				e.cfg.EncodeCaller(ent.Caller, enc)
+			}))
+		}
+		if e.cfg.FunctionKey != "" {
+			appendLogfmt(buf, e.cfg.FunctionKey, ent.Caller.Function)
+		}
+	}
+	if e.cfg.MessageKey != "" {
+		appendLogfmt(buf, e.cfg.MessageKey, ent.Message)
+	}
+	appendLogfmtMap(buf, e.Fields)
+	for i := range fields {
+		m := zapcore.NewMapObjectEncoder()
+		fields[i].AddTo(m)
+		appendLogfmtMap(buf, m.Fields)
+	}
+	if e.cfg.StacktraceKey != "" && ent.Stack != "" {
+		appendLogfmt(buf, e.cfg.StacktraceKey, ent.Stack)
+	}
+	buf.AppendByte('\n')
+	return buf, nil
+}
+
+// primitive returns the value encoded by fn such as zapcore.TimeEncoder.
+func primitive(fn func(zapcore.PrimitiveArrayEncoder)) interface{} {
+	m := zapcore.NewMapObjectEncoder()
+	_ = m.AddArray("v", zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+		fn(enc)
+		return nil
+	}))
+	if values, ok := m.Fields["v"].([]interface{}); ok && len(values) > 0 {
+		return values[0]
+	}
+	return nil
+}
+
+func appendLogfmtMap(buf *buffer.Buffer, fields map[string]interface{}) {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		appendLogfmt(buf, k, fields[k])
+	}
+}
+
+func appendLogfmt(buf *buffer.Buffer, key string, val interface{}) {
+	if buf.Len() > 0 {
+		buf.AppendByte(' ')
+	}
+	buf.AppendString(logfmtQuote(key))
+	buf.AppendByte('=')
+	buf.AppendString(logfmtQuote(logfmtValue(val)))
+}
+
+func logfmtValue(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr, float32, float64:
+		return fmt.Sprint(v)
+	}
+	if b, err := json.Marshal(val); err == nil {
+		return string(b)
+	}
+	return fmt.Sprint(val)
+}
+
+func logfmtQuote(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\\") || strings.IndexFunc(s, func(r rune) bool {
+		return r < ' ' || r == 0x7f
+	}) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
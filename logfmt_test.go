@@ -0,0 +1,60 @@
+package zl
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func Test_logfmtEncoder_EncodeEntry(t *testing.T) {
+	enc := newLogfmtEncoder(*newEncoderConfig())
+	zap.String("version", "v1.0.0").AddTo(enc)
+	clone := enc.Clone()
+	zap.Int("pid", 1).AddTo(clone)
+
+	ent := zapcore.Entry{
+		Level:      ErrorLevel,
+		Time:       time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC),
+		LoggerName: "named",
+		Message:    "SOME_ERROR",
+		Caller:     zapcore.NewEntryCaller(0, "/src/zl/main.go", 10, true),
+		Stack:      "main.main\n\t/src/zl/main.go:10",
+	}
+	buf, err := clone.EncodeEntry(ent, []zapcore.Field{
+		zap.Error(errors.New("some error")),
+		zap.Duration("elapsed", time.Second),
+		zap.Any("tags", []string{"a", "b"}),
+		zap.String("empty", ""),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ""+
+		`timestamp=2026-10-17T12:00:00Z severity=ERROR logger=named caller=zl/main.go:10 function="" `+
+		`message=SOME_ERROR pid=1 version=v1.0.0 error="some error" elapsed=1s tags="[\"a\",\"b\"]" empty="" `+
+		`stacktrace="main.main\n\t/src/zl/main.go:10"`+"\n",
+		buf.String(),
+	)
+}
+
+func Test_logfmtValue(t *testing.T) {
+	tests := []struct {
+		in       interface{}
+		expected string
+	}{
+		{nil, "null"},
+		{"text", "text"},
+		{[]byte("bytes"), "bytes"},
+		{true, "true"},
+		{1.5, "1.5"},
+		{errors.New("err"), "err"},
+		{map[string]interface{}{"a": 1}, `{"a":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, logfmtValue(tt.in))
+		})
+	}
+}
@@ -1,33 +1,38 @@
 package zl
 
 import (
-	"fmt"
-	"log"
-	"os"
-
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 // Logger is a wrapper of Zap's Logger.
 type Logger struct {
-	pretty    *prettyLogger
-	zapLogger *zap.Logger
-	fields    []zap.Field
+	zapLogger  *zap.Logger
+	fields     []zap.Field // fields are encoded in zapLogger, and used for the console and the span events.
+	span       trace.Span
+	callerSkip int // callerSkip is the additional number of callers to skip in the console. See: WithCallerSkip
 }
 
 // New can add additional default fields.
 // e.g. Use this when you want to add a common value in the scope of a context, such as an API request.
+// The Logger writes to the outputs of the current settings, even after Reconfigure.
+// With NopUntilInit, the Logger created before Init starts writing after Init.
 func New(fields ...zap.Field) *Logger {
-	ret := &Logger{
-		pretty:    pretty,
-		zapLogger: newLogger(encoderConfig),
+	checkInit()
+	return &Logger{
+		zapLogger: zap.New(&swapCore{}, newOptions()...).With(fields...),
 		fields:    fields,
 	}
-	if outputType == PrettyOutput {
-		ret.zapLogger = ret.zapLogger.WithOptions(zap.WithFatalHook(fatalHook{}))
+}
+
+func newOptions() []zap.Option {
+	if s := loaded.Load(); s != nil {
+		return s.options
 	}
-	return ret
+	mu.Lock()
+	defer mu.Unlock()
+	return loggerOptions()
 }
 
 // clone creates and returns a shallow copy of the calling Logger instance.
@@ -48,71 +53,136 @@ func (l *Logger) Named(loggerName string) *Logger {
 	}
 	clone := l.clone()
 	clone.zapLogger = clone.zapLogger.Named(loggerName)
-	if outputType == PrettyOutput {
-		clone.pretty = newPrettyLogger(getConsoleOutput(), os.Stderr)
-		clone.pretty.Logger.SetPrefix(fmt.Sprintf("%s | ", clone.zapLogger.Name()))
+	return clone
+}
+
+// With is wrapper of Zap's With.
+// Returns a new Logger with the fields added, without overwriting the existing logger.
+// The fields are encoded once, not every time a log is written.
+//
+// A typical usage would be something like.
+//
+//	reqLogger := zl.New(zap.String("request_id", id))
+//	userLogger := reqLogger.With(zap.Int("user_id", userID))
+func (l *Logger) With(fields ...zap.Field) *Logger {
+	if len(fields) == 0 {
+		return l
 	}
+	clone := l.clone()
+	clone.zapLogger = clone.zapLogger.With(fields...)
+	clone.fields = append(l.fields[:len(l.fields):len(l.fields)], fields...)
+	return clone
+}
+
+// WithOptions is wrapper of Zap's WithOptions.
+// Returns a new Logger with the options applied, without overwriting the existing logger.
+// e.g. zap.Hooks or zap.AddStacktrace.
+// Note that zap.AddCallerSkip does not affect the console of PrettyOutput. Use WithCallerSkip instead.
+func (l *Logger) WithOptions(opts ...zap.Option) *Logger {
+	clone := l.clone()
+	clone.zapLogger = clone.zapLogger.WithOptions(opts...)
+	return clone
+}
+
+// WithCallerSkip returns a new Logger that skips the additional number of callers in the caller field
+// and the console of PrettyOutput.
+// Use this when the Logger is wrapped by a helper function.
+func (l *Logger) WithCallerSkip(skip int) *Logger {
+	clone := l.clone()
+	clone.zapLogger = clone.zapLogger.WithOptions(zap.AddCallerSkip(skip))
+	clone.callerSkip += skip
 	return clone
 }
 
+// Close is the same as Close of the package, as all the Loggers write to the same outputs.
+// It allows the Logger to be used as io.Closer.
+func (l *Logger) Close() error {
+	return Close()
+}
+
 // Debug is wrapper of Zap's Debug.
 func (l *Logger) Debug(message string, fields ...zap.Field) {
-	fields = append(fields, l.fields...)
-	l.logger(message, DebugLevel, fields).Debug(message, fields...)
+	if ce := l.zapLogger.Check(DebugLevel, message); ce != nil {
+		l.write(ce, fields)
+	}
 }
 
 // Info is wrapper of Zap's Info.
 func (l *Logger) Info(message string, fields ...zap.Field) {
-	fields = append(fields, l.fields...)
-	l.logger(message, InfoLevel, fields).Info(message, fields...)
+	if ce := l.zapLogger.Check(InfoLevel, message); ce != nil {
+		l.write(ce, fields)
+	}
 }
 
 // Warn is wrapper of Zap's Warn.
 func (l *Logger) Warn(message string, fields ...zap.Field) {
-	fields = append(fields, l.fields...)
-	l.logger(message, WarnLevel, fields).Warn(message, fields...)
+	if ce := l.zapLogger.Check(WarnLevel, message); ce != nil {
+		l.write(ce, fields)
+	}
 }
 
 // Error is wrapper of Zap's Error.
 func (l *Logger) Error(message string, fields ...zap.Field) {
-	fields = append(fields, l.fields...)
-	l.logger(message, ErrorLevel, fields).Error(message, fields...)
+	if ce := l.zapLogger.Check(ErrorLevel, message); ce != nil {
+		l.write(ce, fields)
+	}
+}
+
+// DPanic is wrapper of Zap's DPanic.
+// It panics after writing the log only in the development mode. See: SetDevelopment
+func (l *Logger) DPanic(message string, fields ...zap.Field) {
+	if ce := l.zapLogger.Check(DPanicLevel, message); ce != nil {
+		l.write(ce, fields)
+	}
+}
+
+// Panic is wrapper of Zap's Panic.
+func (l *Logger) Panic(message string, fields ...zap.Field) {
+	if ce := l.zapLogger.Check(PanicLevel, message); ce != nil {
+		l.write(ce, fields)
+	}
 }
 
 // Fatal is wrapper of Zap's Fatal.
 func (l *Logger) Fatal(message string, fields ...zap.Field) {
-	fields = append(fields, l.fields...)
-	l.logger(message, FatalLevel, fields).Fatal(message, fields...)
+	if ce := l.zapLogger.Check(FatalLevel, message); ce != nil {
+		l.write(ce, fields)
+	}
 }
 
 // DebugErr is Outputs a DEBUG log with error field.
 func (l *Logger) DebugErr(message string, err error, fields ...zap.Field) {
-	fields = append(append(fields, zap.Error(err)), l.fields...)
-	l.loggerErr(message, DebugLevel, err, fields).Debug(message, fields...)
+	if ce := l.zapLogger.Check(DebugLevel, message); ce != nil {
+		l.writeErr(ce, err, fields)
+	}
 }
 
 // InfoErr is Outputs INFO log with error field.
 func (l *Logger) InfoErr(message string, err error, fields ...zap.Field) {
-	fields = append(append(fields, zap.Error(err)), l.fields...)
-	l.loggerErr(message, InfoLevel, err, fields).Info(message, fields...)
+	if ce := l.zapLogger.Check(InfoLevel, message); ce != nil {
+		l.writeErr(ce, err, fields)
+	}
 }
 
 // WarnErr is Outputs WARN log with error field.
 func (l *Logger) WarnErr(message string, err error, fields ...zap.Field) {
-	fields = append(append(fields, zap.Error(err)), l.fields...)
-	l.loggerErr(message, WarnLevel, err, fields).Warn(message, fields...)
+	if ce := l.zapLogger.Check(WarnLevel, message); ce != nil {
+		l.writeErr(ce, err, fields)
+	}
 }
 
 // ErrorErr is Outputs ERROR log with error field.
 func (l *Logger) ErrorErr(message string, err error, fields ...zap.Field) {
-	fields = append(append(fields, zap.Error(err)), l.fields...)
-	l.loggerErr(message, ErrorLevel, err, fields).Error(message, fields...)
+	if ce := l.zapLogger.Check(ErrorLevel, message); ce != nil {
+		l.writeErr(ce, err, fields)
+	}
 }
 
 // Err is alias of ErrorErr.
 func (l *Logger) Err(message string, err error, fields ...zap.Field) {
-	fields = append(append(fields, zap.Error(err)), l.fields...)
-	l.loggerErr(message, ErrorLevel, err, fields).Error(message, fields...)
+	if ce := l.zapLogger.Check(ErrorLevel, message); ce != nil {
+		l.writeErr(ce, err, fields)
+	}
 }
 
 // ErrRet write error log and return error.
@@ -122,79 +192,143 @@ func (l *Logger) Err(message string, err error, fields ...zap.Field) {
 //	  return zl.ErrRet("SOME_ERROR", fmt.Error("some message err: %w",err))
 //	}
 func (l *Logger) ErrRet(message string, err error, fields ...zap.Field) error {
-	fields = append(append(fields, zap.Error(err)), l.fields...)
-	l.loggerErr(message, ErrorLevel, err, fields).Error(message, fields...)
+	if ce := l.zapLogger.Check(ErrorLevel, message); ce != nil {
+		l.writeErr(ce, err, fields)
+	}
 	return err
 }
 
+// DPanicErr is Outputs DPANIC log with error field.
+// It panics after writing the log only in the development mode. See: SetDevelopment
+func (l *Logger) DPanicErr(message string, err error, fields ...zap.Field) {
+	if ce := l.zapLogger.Check(DPanicLevel, message); ce != nil {
+		l.writeErr(ce, err, fields)
+	}
+}
+
+// PanicErr is Outputs PANIC log with error field.
+func (l *Logger) PanicErr(message string, err error, fields ...zap.Field) {
+	if ce := l.zapLogger.Check(PanicLevel, message); ce != nil {
+		l.writeErr(ce, err, fields)
+	}
+}
+
 // FatalErr is Outputs ERROR log with error field.
 func (l *Logger) FatalErr(message string, err error, fields ...zap.Field) {
-	fields = append(append(fields, zap.Error(err)), l.fields...)
-	l.loggerErr(message, FatalLevel, err, fields).Fatal(message, fields...)
+	if ce := l.zapLogger.Check(FatalLevel, message); ce != nil {
+		l.writeErr(ce, err, fields)
+	}
 }
 
-func (l *Logger) logger(message string, level zapcore.Level, fields []zap.Field) *zap.Logger {
-	if l.pretty != nil {
-		l.pretty.log(message, level, fields)
+// write writes the checked entry to the console of PrettyOutput, the span and the zap cores.
+// The fields of the Logger are added only when they are used, as they are already encoded in the zap cores.
+func (l *Logger) write(ce *zapcore.CheckedEntry, fields []zap.Field) {
+	if pretty := l.prettyLogger(); pretty != nil || l.span != nil {
+		all := append(fields[:len(fields):len(fields)], l.fields...)
+		pretty.log(ce.Message, ce.Level, all)
+		l.addSpanEvent(ce.Message, ce.Level, nil, all)
 	}
-	return l.zapLogger
+	ce.Write(fields...)
 }
 
-func (l *Logger) loggerErr(message string, level zapcore.Level, err error, fields []zap.Field) *zap.Logger {
-	if l.pretty != nil {
-		l.pretty.logWithError(message, level, err, fields)
+func (l *Logger) writeErr(ce *zapcore.CheckedEntry, err error, fields []zap.Field) {
+	fields = append(fields, zap.Error(err))
+	if pretty := l.prettyLogger(); pretty != nil || l.span != nil {
+		all := append(fields[:len(fields):len(fields)], l.fields...)
+		pretty.logWithError(ce.Message, ce.Level, err, all)
+		l.addSpanEvent(ce.Message, ce.Level, err, all)
 	}
-	return l.zapLogger
+	ce.Write(fields...)
+}
+
+// prettyLogger returns the console of PrettyOutput for the Logger, or nil if the output type is not PrettyOutput.
+func (l *Logger) prettyLogger() *prettyLogger {
+	return loadState().pretty.derive(l.zapLogger.Name(), l.callerSkip)
 }
 
 // Debug is wrapper of Zap's Debug.
 func Debug(message string, fields ...zap.Field) {
-	logger(message, DebugLevel, fields).Debug(message, fields...)
+	if ce := logger().Check(DebugLevel, message); ce != nil {
+		write(ce, fields)
+	}
 }
 
 // Info is wrapper of Zap's Info.
 func Info(message string, fields ...zap.Field) {
-	logger(message, InfoLevel, fields).Info(message, fields...)
+	if ce := logger().Check(InfoLevel, message); ce != nil {
+		write(ce, fields)
+	}
 }
 
 // Warn is wrapper of Zap's Warn.
 func Warn(message string, fields ...zap.Field) {
-	logger(message, WarnLevel, fields).Warn(message, fields...)
+	if ce := logger().Check(WarnLevel, message); ce != nil {
+		write(ce, fields)
+	}
 }
 
 // Error is wrapper of Zap's Error.
 func Error(message string, fields ...zap.Field) {
-	logger(message, ErrorLevel, fields).Error(message, fields...)
+	if ce := logger().Check(ErrorLevel, message); ce != nil {
+		write(ce, fields)
+	}
+}
+
+// DPanic is wrapper of Zap's DPanic.
+// It panics after writing the log only in the development mode. See: SetDevelopment
+func DPanic(message string, fields ...zap.Field) {
+	if ce := logger().Check(DPanicLevel, message); ce != nil {
+		write(ce, fields)
+	}
+}
+
+// Panic is wrapper of Zap's Panic.
+func Panic(message string, fields ...zap.Field) {
+	if ce := logger().Check(PanicLevel, message); ce != nil {
+		write(ce, fields)
+	}
 }
 
 // Fatal is wrapper of Zap's Fatal.
 func Fatal(message string, fields ...zap.Field) {
-	logger(message, FatalLevel, fields).Fatal(message, fields...)
+	if ce := logger().Check(FatalLevel, message); ce != nil {
+		write(ce, fields)
+	}
 }
 
 // DebugErr is Outputs a DEBUG log with error field.
 func DebugErr(message string, err error, fields ...zap.Field) {
-	loggerErr(message, DebugLevel, err, fields).Debug(message, append(fields, zap.Error(err))...)
+	if ce := logger().Check(DebugLevel, message); ce != nil {
+		writeErr(ce, err, fields)
+	}
 }
 
 // InfoErr is Outputs INFO log with error field.
 func InfoErr(message string, err error, fields ...zap.Field) {
-	loggerErr(message, InfoLevel, err, fields).Info(message, append(fields, zap.Error(err))...)
+	if ce := logger().Check(InfoLevel, message); ce != nil {
+		writeErr(ce, err, fields)
+	}
 }
 
 // WarnErr is Outputs WARN log with error field.
 func WarnErr(message string, err error, fields ...zap.Field) {
-	loggerErr(message, WarnLevel, err, fields).Warn(message, append(fields, zap.Error(err))...)
+	if ce := logger().Check(WarnLevel, message); ce != nil {
+		writeErr(ce, err, fields)
+	}
 }
 
 // ErrorErr is Outputs ERROR log with error field.
 func ErrorErr(message string, err error, fields ...zap.Field) {
-	loggerErr(message, ErrorLevel, err, fields).Error(message, append(fields, zap.Error(err))...)
+	if ce := logger().Check(ErrorLevel, message); ce != nil {
+		writeErr(ce, err, fields)
+	}
 }
 
 // Err is alias of ErrorErr.
 func Err(message string, err error, fields ...zap.Field) {
-	loggerErr(message, ErrorLevel, err, fields).Error(message, append(fields, zap.Error(err))...)
+	if ce := logger().Check(ErrorLevel, message); ce != nil {
+		writeErr(ce, err, fields)
+	}
 }
 
 // ErrRet write error log and return error.
@@ -204,13 +338,32 @@ func Err(message string, err error, fields ...zap.Field) {
 //	  return zl.ErrRet("SOME_ERROR", fmt.Error("some message err: %w",err))
 //	}
 func ErrRet(message string, err error, fields ...zap.Field) error {
-	loggerErr(message, ErrorLevel, err, fields).Error(message, append(fields, zap.Error(err))...)
+	if ce := logger().Check(ErrorLevel, message); ce != nil {
+		writeErr(ce, err, fields)
+	}
 	return err
 }
 
+// DPanicErr is Outputs DPANIC log with error field.
+// It panics after writing the log only in the development mode. See: SetDevelopment
+func DPanicErr(message string, err error, fields ...zap.Field) {
+	if ce := logger().Check(DPanicLevel, message); ce != nil {
+		writeErr(ce, err, fields)
+	}
+}
+
+// PanicErr is Outputs PANIC log with error field.
+func PanicErr(message string, err error, fields ...zap.Field) {
+	if ce := logger().Check(PanicLevel, message); ce != nil {
+		writeErr(ce, err, fields)
+	}
+}
+
 // FatalErr is Outputs ERROR log with error field.
 func FatalErr(message string, err error, fields ...zap.Field) {
-	loggerErr(message, FatalLevel, err, fields).Fatal(message, append(fields, zap.Error(err))...)
+	if ce := logger().Check(FatalLevel, message); ce != nil {
+		writeErr(ce, err, fields)
+	}
 }
 
 // Dump is a deep pretty printer for Go data structures to aid in debugging.
@@ -220,33 +373,33 @@ func FatalErr(message string, err error, fields ...zap.Field) {
 // See: https://github.com/davecgh/go-spew
 func Dump(a ...interface{}) {
 	checkInit()
-	pretty.dump(a...)
+	loadState().pretty.dump(a...)
 }
 
-func logger(message string, level zapcore.Level, fields []zap.Field) *zap.Logger {
+// logger returns the global logger. The level must be checked with its Check method
+// before the fields are processed, so that the disabled logs cost nothing.
+func logger() *zap.Logger {
 	checkInit()
-	pretty.log(message, level, fields)
-	return zapLogger
+	return loadState().zapLogger
 }
 
-func iDebug(message string, fields ...zap.Field) {
-	iLogger(message, DebugLevel, fields).Debug(message, fields...)
+func write(ce *zapcore.CheckedEntry, fields []zap.Field) {
+	loadState().pretty.log(ce.Message, ce.Level, fields)
+	ce.Write(fields...)
 }
 
-func iLogger(message string, level zapcore.Level, fields []zap.Field) *zap.Logger {
-	checkInit()
-	pretty.log(message, level, fields)
-	return internalLogger
+func writeErr(ce *zapcore.CheckedEntry, err error, fields []zap.Field) {
+	loadState().pretty.logWithError(ce.Message, ce.Level, err, fields)
+	ce.Write(append(fields, zap.Error(err))...)
 }
 
-func loggerErr(message string, level zapcore.Level, err error, fields []zap.Field) *zap.Logger {
-	checkInit()
-	pretty.logWithError(message, level, err, fields)
-	return zapLogger
+func iDebug(message string, fields ...zap.Field) {
+	if ce := iLogger().Check(DebugLevel, message); ce != nil {
+		write(ce, fields)
+	}
 }
 
-func checkInit() {
-	if zapLogger == nil {
-		log.Fatal("The logger is not initialized. Init() must be called.")
-	}
+func iLogger() *zap.Logger {
+	checkInit()
+	return loadState().internalLogger
 }
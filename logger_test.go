@@ -0,0 +1,204 @@
+package zl
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLogger_With(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetRotateFileName(fileName)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, PIDKey, StacktraceKey)
+	defer ResetGlobalLoggerSettings()
+	Init()
+
+	parent := New(zap.String("request_id", "1"))
+	child := parent.With(zap.Int("user_id", 42))
+	assert.Same(t, parent, parent.With())
+	child.Info("CHILD_INFO", zap.Bool("ok", true))
+	child.Named("api").ErrorErr("CHILD_ERROR", assert.AnError)
+	parent.Info("PARENT_INFO")
+	Sync()
+
+	b, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	assert.Equal(t, []string{
+		`{"severity":"INFO","message":"CHILD_INFO","request_id":"1","user_id":42,"ok":true}`,
+		`{"severity":"ERROR","logger":"api","message":"CHILD_ERROR","request_id":"1","user_id":42,` +
+			`"error":"assert.AnError general error for testing"}`,
+		`{"severity":"INFO","message":"PARENT_INFO","request_id":"1"}`,
+	}, lines)
+}
+
+func TestLogger_WithOptions(t *testing.T) {
+	var messages []string
+	SetOutput(ConsoleOutput)
+	defer ResetGlobalLoggerSettings()
+	Init()
+
+	l := New().WithOptions(zap.Hooks(func(entry zapcore.Entry) error {
+		messages = append(messages, entry.Message)
+		return nil
+	}))
+	l.Info("HOOKED")
+	New().Info("NOT_HOOKED")
+	assert.Equal(t, []string{"HOOKED"}, messages)
+}
+
+func TestLogger_WithCallerSkip(t *testing.T) {
+	var buf bytes.Buffer
+	fileName := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(PrettyOutput)
+	SetRotateFileName(fileName)
+	SetOmitKeys(TimeKey, FunctionKey, VersionKey, HostnameKey, PIDKey)
+	defer ResetGlobalLoggerSettings()
+	Init()
+	pretty := loadState().pretty
+	pretty.Logger.SetOutput(&buf)
+	l := New().WithCallerSkip(1)
+	logInfo := func(message string) {
+		l.Info(message)
+	}
+	logInfo("SKIPPED")
+	Sync()
+
+	b, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	assert.Equal(t, `{"severity":"INFO","caller":"zl/logger_test.go:72","message":"SKIPPED"}`, lines[len(lines)-1])
+	assert.Equal(t, "logger_test.go:72: "+pretty.coloredLevel(InfoLevel).String()+" SKIPPED\n", buf.String())
+}
+
+func TestLogger_disabledLevel(t *testing.T) {
+	for _, output := range []Output{PrettyOutput, ConsoleAndFileOutput, ConsoleOutput, FileOutput} {
+		t.Run(output.String(), func(t *testing.T) {
+			ResetGlobalLoggerSettings()
+			SetOutput(output)
+			SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+			defer ResetGlobalLoggerSettings()
+			Init()
+			l := New(zap.String("request_id", "1"))
+			sugar := l.Sugar()
+			err := errors.New("some error")
+
+			assert.Zero(t, testing.AllocsPerRun(100, func() {
+				Debug("DEBUG_MESSAGE")
+				DebugErr("DEBUG_MESSAGE", err)
+				l.Debug("DEBUG_MESSAGE")
+				l.DebugErr("DEBUG_MESSAGE", err)
+				Debugf("DEBUG_MESSAGE %d", 1)
+				sugar.Debugw("DEBUG_MESSAGE")
+			}))
+		})
+	}
+}
+
+var benchmarkOutputs = []Output{PrettyOutput, ConsoleAndFileOutput, ConsoleOutput, FileOutput}
+
+// runBenchmark runs the benchmark in each output type.
+// The console output is discarded, and the file is written to the temporary directory.
+func runBenchmark(b *testing.B, fn func(b *testing.B)) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer devNull.Close()
+	for _, output := range benchmarkOutputs {
+		b.Run(output.String(), func(b *testing.B) {
+			stderr := os.Stderr
+			os.Stderr = devNull
+			defer func() { os.Stderr = stderr }()
+			ResetGlobalLoggerSettings()
+			SetOutput(output)
+			SetRotateFileName(filepath.Join(b.TempDir(), "app.jsonl"))
+			defer ResetGlobalLoggerSettings()
+			Init()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			fn(b)
+		})
+	}
+}
+
+func BenchmarkInfo(b *testing.B) {
+	runBenchmark(b, func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Info("BENCHMARK", zap.String("key", "value"), zap.Int("count", i))
+		}
+	})
+}
+
+func BenchmarkErr(b *testing.B) {
+	err := errors.New("some error")
+	runBenchmark(b, func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Err("BENCHMARK", err, zap.Int("count", i))
+		}
+	})
+}
+
+func BenchmarkDebug_disabled(b *testing.B) {
+	runBenchmark(b, func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Debug("BENCHMARK", zap.String("key", "value"), zap.Int("count", i))
+		}
+	})
+}
+
+func BenchmarkLogger_Info(b *testing.B) {
+	runBenchmark(b, func(b *testing.B) {
+		l := New(zap.String("request_id", "1"))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			l.Info("BENCHMARK", zap.String("key", "value"), zap.Int("count", i))
+		}
+	})
+}
+
+func BenchmarkLogger_Err(b *testing.B) {
+	err := errors.New("some error")
+	runBenchmark(b, func(b *testing.B) {
+		l := New(zap.String("request_id", "1"))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			l.Err("BENCHMARK", err, zap.Int("count", i))
+		}
+	})
+}
+
+func BenchmarkLogger_Debug_disabled(b *testing.B) {
+	runBenchmark(b, func(b *testing.B) {
+		l := New(zap.String("request_id", "1"))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			l.Debug("BENCHMARK", zap.String("key", "value"), zap.Int("count", i))
+		}
+	})
+}
+
+func BenchmarkInfow(b *testing.B) {
+	runBenchmark(b, func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Infow("BENCHMARK", "key", "value", "count", i)
+		}
+	})
+}
+
+func BenchmarkDebugf_disabled(b *testing.B) {
+	runBenchmark(b, func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Debugf("BENCHMARK %s %d", "value", i)
+		}
+	})
+}
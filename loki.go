@@ -0,0 +1,145 @@
+package zl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var lokiLabelName = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// LokiOptions is the settings of the Loki sink.
+type LokiOptions struct {
+	// URL is the URL of the push API. Default is "http://localhost:3100/loki/api/v1/push".
+	URL string
+	// Labels are the static labels added to all streams. e.g. map[string]string{"job": "api"}
+	Labels map[string]string
+	// LabelKeys are the field keys used as the labels of the streams.
+	// They should be low-cardinality fields. Default is the severity, logger, version and hostname fields.
+	LabelKeys []string
+	// TenantID is set to the X-Scope-OrgID header for multi-tenant Loki.
+	TenantID string
+	// Headers are added to the requests. e.g. authorization headers.
+	Headers map[string]string
+	// Timeout is the timeout of a request. Default is 10 seconds.
+	Timeout time.Duration
+	// Batch is the settings of batching.
+	Batch BatchOptions
+}
+
+// LokiWriter is a zapcore.WriteSyncer that pushes the entries encoded by JSONEncoder to Grafana Loki.
+// The JSON entry is the log line, and the entries are grouped into the streams by the labels.
+// See: https://grafana.com/docs/loki/latest/reference/loki-http-api/#ingest-logs
+//
+// A typical usage would be something like.
+//
+//	w := zl.NewLokiWriter(zl.LokiOptions{URL: "http://localhost:3100/loki/api/v1/push", Labels: map[string]string{"job": "api"}})
+//	zl.AddSink("loki", w, zl.SinkOptions{Level: zl.InfoLevel})
+//	zl.Init()
+//	defer w.Close()
+type LokiWriter struct {
+	*batchWriter
+	options LokiOptions
+	client  *http.Client
+}
+
+// NewLokiWriter returns the LokiWriter.
+func NewLokiWriter(options LokiOptions) *LokiWriter {
+	if options.URL == "" {
+		options.URL = "http://localhost:3100/loki/api/v1/push"
+	}
+	if options.LabelKeys == nil {
+		options.LabelKeys = []string{
+			fieldKey(LevelKey), fieldKey(LoggerKey), fieldKey(VersionKey), fieldKey(HostnameKey),
+		}
+	}
+	if options.Timeout == 0 {
+		options.Timeout = 10 * time.Second
+	}
+	w := &LokiWriter{options: options, client: &http.Client{Timeout: options.Timeout}}
+	w.batchWriter = newBatchWriter(options.Batch, dropNonJSON(w.send))
+	return w
+}
+
+// requiresJSON implements jsonSink.
+func (w *LokiWriter) requiresJSON() {}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (w *LokiWriter) send(entries [][]byte) error {
+	body, err := json.Marshal(map[string]interface{}{"streams": w.streams(entries)})
+	if err != nil {
+		return &permanentError{err: err}
+	}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, w.options.URL, bytes.NewReader(body))
+	if err != nil {
+		return &permanentError{err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.options.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", w.options.TenantID)
+	}
+	for k, v := range w.options.Headers {
+		req.Header.Set(k, v)
+	}
+	return postRequest(w.client, req)
+}
+
+// streams groups the entries by the labels.
+func (w *LokiWriter) streams(entries [][]byte) []*lokiStream {
+	var streams []*lokiStream
+	index := make(map[string]*lokiStream)
+	for _, p := range entries {
+		m, err := parseEntry(p)
+		if err != nil {
+			continue
+		}
+		labels := w.labels(m)
+		key := lokiStreamKey(labels)
+		if index[key] == nil {
+			index[key] = &lokiStream{Stream: labels}
+			streams = append(streams, index[key])
+		}
+		index[key].Values = append(index[key].Values, [2]string{
+			strconv.FormatInt(entryTime(m).UnixNano(), 10),
+			string(bytes.TrimRight(p, "\n")),
+		})
+	}
+	return streams
+}
+
+func (w *LokiWriter) labels(m map[string]interface{}) map[string]string {
+	labels := make(map[string]string, len(w.options.Labels)+len(w.options.LabelKeys))
+	for k, v := range w.options.Labels {
+		labels[k] = v
+	}
+	for _, k := range w.options.LabelKeys {
+		if v, ok := m[k]; ok {
+			labels[lokiLabelName.ReplaceAllString(k, "_")] = logfmtValue(v)
+		}
+	}
+	return labels
+}
+
+func lokiStreamKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s=%q,", k, labels[k])
+	}
+	return b.String()
+}
@@ -0,0 +1,95 @@
+package zl
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestLokiWriter(t *testing.T) {
+	type pushRequest struct {
+		header http.Header
+		body   []byte
+	}
+	requests := make(chan pushRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/loki/api/v1/push", r.URL.Path)
+		b, _ := io.ReadAll(r.Body)
+		requests <- pushRequest{r.Header, b}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	w := NewLokiWriter(LokiOptions{
+		URL:      server.URL + "/loki/api/v1/push",
+		Labels:   map[string]string{"job": "test"},
+		TenantID: "tenant1",
+		Batch:    BatchOptions{Size: 3, Interval: time.Hour},
+	})
+	defer w.Close()
+	SetOutput(ConsoleOutput)
+	SetVersion("v1.0.0")
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, HostnameKey, StacktraceKey, PIDKey)
+	AddSink("loki", w, SinkOptions{})
+	defer ResetGlobalLoggerSettings()
+	Init()
+
+	Info("INFO_MESSAGE", zap.String("user", "Alice"))
+	Warn("WARN_MESSAGE")
+	New().Named("api").Info("NAMED_MESSAGE")
+	Info("AFTER_FULL")
+
+	// the batch is full.
+	req := <-requests
+	assert.Equal(t, "application/json", req.header.Get("Content-Type"))
+	assert.Equal(t, "tenant1", req.header.Get("X-Scope-OrgID"))
+
+	var body struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	assert.NoError(t, json.Unmarshal(req.body, &body))
+	assert.Len(t, body.Streams, 3)
+	assert.Equal(t, map[string]string{"job": "test", "severity": "INFO", "version": "v1.0.0"}, body.Streams[0].Stream)
+	assert.Equal(t, map[string]string{"job": "test", "severity": "WARN", "version": "v1.0.0"}, body.Streams[1].Stream)
+	assert.Equal(t, map[string]string{"job": "test", "severity": "INFO", "version": "v1.0.0", "logger": "api"}, body.Streams[2].Stream)
+	assert.Len(t, body.Streams[0].Values, 1)
+	assert.NotEmpty(t, body.Streams[0].Values[0][0])
+	assert.Equal(t,
+		`{"severity":"INFO","message":"INFO_MESSAGE","version":"v1.0.0","user":"Alice"}`,
+		body.Streams[0].Values[0][1],
+	)
+
+	// the rest is sent by Sync.
+	assert.NoError(t, w.Sync())
+	req = <-requests
+	assert.NoError(t, json.Unmarshal(req.body, &body))
+	assert.Len(t, body.Streams, 1)
+	assert.Contains(t, body.Streams[0].Values[0][1], "AFTER_FULL")
+}
+
+func TestLokiWriter_labels(t *testing.T) {
+	w := NewLokiWriter(LokiOptions{LabelKeys: []string{"service.name", "status"}})
+	defer w.Close()
+
+	streams := w.streams([][]byte{
+		[]byte(`{"timestamp":"2026-10-17T12:00:00Z","service.name":"api","status":200}` + "\n"),
+		[]byte(`{"timestamp":"2026-10-17T12:00:01Z","service.name":"api","status":200}` + "\n"),
+		[]byte(`{"timestamp":"2026-10-17T12:00:02Z","service.name":"api"}` + "\n"),
+	})
+	assert.Len(t, streams, 2)
+	assert.Equal(t, map[string]string{"service_name": "api", "status": "200"}, streams[0].Stream)
+	assert.Equal(t, [][2]string{
+		{"1792238400000000000", `{"timestamp":"2026-10-17T12:00:00Z","service.name":"api","status":200}`},
+		{"1792238401000000000", `{"timestamp":"2026-10-17T12:00:01Z","service.name":"api","status":200}`},
+	}, streams[0].Values)
+	assert.Equal(t, map[string]string{"service_name": "api"}, streams[1].Stream)
+}
@@ -0,0 +1,218 @@
+package zl
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+// msgpackEventTime is the EventTime extension type of the Fluent Forward protocol.
+type msgpackEventTime time.Time
+
+// appendMsgpack appends the value encoded in MessagePack.
+// It supports the values decoded by parseEntry, []byte and msgpackEventTime.
+// See: https://github.com/msgpack/msgpack/blob/master/spec.md
+func appendMsgpack(b []byte, val interface{}) []byte {
+	switch v := val.(type) {
+	case nil:
+		return append(b, 0xc0)
+	case bool:
+		if v {
+			return append(b, 0xc3)
+		}
+		return append(b, 0xc2)
+	case int:
+		return appendMsgpackInt(b, int64(v))
+	case int64:
+		return appendMsgpackInt(b, v)
+	case float64:
+		return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(v))
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return appendMsgpackInt(b, i)
+		}
+		f, _ := v.Float64()
+		return appendMsgpack(b, f)
+	case string:
+		b = appendMsgpackLen(b, len(v), 0xa0, 31, 0xd9, 0xda, 0xdb)
+		return append(b, v...)
+	case []byte:
+		b = appendMsgpackLen(b, len(v), 0, -1, 0xc4, 0xc5, 0xc6)
+		return append(b, v...)
+	case []interface{}:
+		b = appendMsgpackLen(b, len(v), 0x90, 15, 0, 0xdc, 0xdd)
+		for i := range v {
+			b = appendMsgpack(b, v[i])
+		}
+		return b
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b = appendMsgpackLen(b, len(v), 0x80, 15, 0, 0xde, 0xdf)
+		for _, k := range keys {
+			b = appendMsgpack(appendMsgpack(b, k), v[k])
+		}
+		return b
+	case msgpackEventTime:
+		t := time.Time(v)
+		b = append(b, 0xd7, 0x00)
+		b = binary.BigEndian.AppendUint32(b, uint32(t.Unix()))
+		return binary.BigEndian.AppendUint32(b, uint32(t.Nanosecond()))
+	default:
+		return appendMsgpack(b, fmt.Sprint(v))
+	}
+}
+
+func appendMsgpackInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0 && v <= 0x7f:
+		return append(b, byte(v))
+	case v < 0 && v >= -32:
+		return append(b, byte(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v))
+	}
+}
+
+// appendMsgpackLen appends the header of a string, a binary, an array or a map.
+// fix is the prefix of the fix format that holds the length up to fixMax.
+// A zero prefix means that the format is not available.
+func appendMsgpackLen(b []byte, n int, fix byte, fixMax int, p8, p16, p32 byte) []byte {
+	switch {
+	case n <= fixMax:
+		return append(b, fix|byte(n))
+	case n <= math.MaxUint8 && p8 != 0:
+		return append(b, p8, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, p16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, p32), uint32(n))
+	}
+}
+
+// readMsgpack reads a MessagePack value.
+// Maps are decoded to map[string]interface{}, integers to int64, and extensions to []byte.
+func readMsgpack(r *bufio.Reader) (interface{}, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return readMsgpackMap(r, int(c&0x0f))
+	case c&0xf0 == 0x90:
+		return readMsgpackArray(r, int(c&0x0f))
+	case c&0xe0 == 0xa0:
+		return readMsgpackBytes(r, int(c&0x1f), true)
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xd9:
+		n, err := readMsgpackUint(r, 1)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackBytes(r, int(n), c == 0xd9)
+	case 0xc5, 0xda:
+		n, err := readMsgpackUint(r, 2)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackBytes(r, int(n), c == 0xda)
+	case 0xc6, 0xdb:
+		n, err := readMsgpackUint(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackBytes(r, int(n), c == 0xdb)
+	case 0xcb:
+		n, err := readMsgpackUint(r, 8)
+		return math.Float64frombits(n), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := readMsgpackUint(r, 1<<(c-0xcc))
+		return int64(n), err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		n, err := readMsgpackUint(r, size)
+		return int64(n<<(64-8*size)) >> (64 - 8*size), err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return readMsgpackBytes(r, 1+1<<(c-0xd4), false)
+	case 0xdc, 0xdd:
+		n, err := readMsgpackUint(r, 2<<(c-0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackArray(r, int(n))
+	case 0xde, 0xdf:
+		n, err := readMsgpackUint(r, 2<<(c-0xde))
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackMap(r, int(n))
+	}
+	return nil, fmt.Errorf("unsupported msgpack format: 0x%x", c)
+}
+
+func readMsgpackUint(r *bufio.Reader, size int) (uint64, error) {
+	buf := make([]byte, 8)
+	if _, err := io.ReadFull(r, buf[8-size:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf), nil
+}
+
+func readMsgpackBytes(r *bufio.Reader, n int, isString bool) (interface{}, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	if isString {
+		return string(buf), nil
+	}
+	return buf, nil
+}
+
+func readMsgpackArray(r *bufio.Reader, n int) (interface{}, error) {
+	arr := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		v, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+	}
+	return arr, nil
+}
+
+func readMsgpackMap(r *bufio.Reader, n int) (interface{}, error) {
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		v, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		m[fmt.Sprint(k)] = v
+	}
+	return m, nil
+}
@@ -0,0 +1,60 @@
+package zl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_appendMsgpack(t *testing.T) {
+	tests := []struct {
+		name     string
+		val      interface{}
+		expected interface{}
+	}{
+		{"nil", nil, nil},
+		{"bool", true, true},
+		{"positive fixint", 1, int64(1)},
+		{"negative fixint", -1, int64(-1)},
+		{"int64", json.Number("-1234567890123"), int64(-1234567890123)},
+		{"float64", json.Number("1.5"), 1.5},
+		{"fixstr", "abc", "abc"},
+		{"str8", strings.Repeat("a", 32), strings.Repeat("a", 32)},
+		{"str16", strings.Repeat("a", 256), strings.Repeat("a", 256)},
+		{"bin8", []byte("abc"), []byte("abc")},
+		{"array", []interface{}{"a", 1}, []interface{}{"a", int64(1)}},
+		{"array16", make([]interface{}, 16), make([]interface{}, 16)},
+		{
+			"map",
+			map[string]interface{}{"b": map[string]interface{}{"c": false}, "a": "x"},
+			map[string]interface{}{"b": map[string]interface{}{"c": false}, "a": "x"},
+		},
+		{
+			"event time",
+			msgpackEventTime(time.Unix(1, 2)),
+			[]byte{0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := appendMsgpack(nil, tt.val)
+			r := bufio.NewReader(bytes.NewReader(b))
+			actual, err := readMsgpack(r)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+			assert.Zero(t, r.Buffered())
+		})
+	}
+}
+
+func Test_appendMsgpack_sortedKeys(t *testing.T) {
+	assert.Equal(t,
+		[]byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'b', 0x02},
+		appendMsgpack(nil, map[string]interface{}{"b": 2, "a": 1}),
+	)
+}
@@ -0,0 +1,113 @@
+package zl
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// netConn is a connection to a log server used by the network sinks.
+// It reconnects when writing fails.
+type netConn struct {
+	mu      sync.Mutex
+	network string
+	address string
+	timeout time.Duration // timeout is used for connecting and for each write, so that logging does not block on a stalled server.
+	conn    net.Conn
+}
+
+func dialNetConn(network, address string, timeout time.Duration) (*netConn, error) {
+	c := &netConn{network: network, address: address, timeout: timeout}
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *netConn) connect() error {
+	if c.conn != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+	}
+	conn, err := net.DialTimeout(c.network, c.address, c.timeout)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	return nil
+}
+
+// write writes the messages in order. Each message is a datagram on the packet connections.
+// When writing fails, it reconnects and retries once from the failed message.
+func (c *netConn) write(msgs ...[]byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		if err := c.connect(); err != nil {
+			return err
+		}
+	}
+	for _, msg := range msgs {
+		if err := c.writeMsg(msg); err != nil {
+			if err := c.connect(); err != nil {
+				return err
+			}
+			if err := c.writeMsg(msg); err != nil {
+				_ = c.conn.Close()
+				c.conn = nil
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeMsg writes the message with the write deadline. A timeout is handled like the other write errors.
+func (c *netConn) writeMsg(msg []byte) error {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(msg)
+	return err
+}
+
+// do runs fn with the connection. The connection is closed when fn fails, and reconnected at the next call.
+func (c *netConn) do(fn func(conn net.Conn) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		if err := c.connect(); err != nil {
+			return err
+		}
+	}
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+		return err
+	}
+	if err := fn(c.conn); err != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (c *netConn) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+func (c *netConn) isStream() bool {
+	switch c.network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	}
+	return false
+}
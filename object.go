@@ -0,0 +1,295 @@
+package zl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// ObjectTagName is the struct tag name used by Object and NamedObject.
+	//
+	// Supported options (comma separated):
+	//
+	//	zl:"-"            // the field is never logged.
+	//	zl:"mask"         // the value is replaced with MaskedValue.
+	//	zl:"hash"         // the value is replaced with its SHA-256 hex digest.
+	//	zl:"name=user_id" // the field is logged with the key "user_id".
+	//	zl:"console"      // the value is also displayed to console when output type is pretty.
+	ObjectTagName = "zl"
+
+	// MaskedValue is the value written instead of fields tagged with `zl:"mask"`.
+	MaskedValue = "***"
+
+	// maxObjectDepth is the maximum nesting of the structs written by Object, which stops the cyclic references.
+	maxObjectDepth = 32
+)
+
+var (
+	errObjectTooDeep  = fmt.Errorf("the object is nested deeper than %d levels, or has a cyclic reference", maxObjectDepth)
+	objectFieldsCache sync.Map // map[reflect.Type][]objectField
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// objectField is the parsed zl tag of an exported struct field.
+type objectField struct {
+	index   []int
+	key     string
+	mask    bool
+	hash    bool
+	console bool
+}
+
+// objectMarshaler is a zapcore.ObjectMarshaler built from zl struct tags.
+type objectMarshaler struct {
+	val    reflect.Value
+	fields []objectField
+	depth  int // depth is the nesting from the object passed to Object or NamedObject.
+}
+
+// Object adds the exported fields of a struct to the log entry, honoring zl struct tags.
+// The fields are written at the top level of the entry, like zap.Inline.
+// Struct fields are inspected with reflection only once per type and then cached.
+//
+// A typical usage would be something like.
+//
+//	type User struct {
+//	  ID       int    `zl:"name=user_id,console"`
+//	  Email    string `zl:"hash"`
+//	  Password string `zl:"-"`
+//	  Token    string `zl:"mask"`
+//	}
+//	zl.Info("USER_CREATED", zl.Object(user))
+func Object(val interface{}) zap.Field {
+	return zap.Inline(newObjectMarshaler(reflect.ValueOf(val), 0))
+}
+
+// NamedObject is similar to Object, but writes the fields of a struct as a nested object under the key.
+func NamedObject(key string, val interface{}) zap.Field {
+	return zap.Object(key, newObjectMarshaler(reflect.ValueOf(val), 0))
+}
+
+func newObjectMarshaler(v reflect.Value, depth int) *objectMarshaler {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return &objectMarshaler{}
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return &objectMarshaler{}
+	}
+	return &objectMarshaler{val: v, fields: getObjectFields(v.Type()), depth: depth}
+}
+
+func getObjectFields(t reflect.Type) []objectField {
+	if cached, ok := objectFieldsCache.Load(t); ok {
+		return cached.([]objectField)
+	}
+	var fields []objectField
+	var skipped [][]int // skipped is the index of the embedded structs tagged with `zl:"-"`.
+	for _, sf := range reflect.VisibleFields(t) {
+		if isPromotedFrom(sf.Index, skipped) {
+			continue
+		}
+		tag := sf.Tag.Get(ObjectTagName)
+		if sf.Anonymous && indirectType(sf.Type).Kind() == reflect.Struct {
+			// the fields promoted from the embedded struct are skipped as well.
+			if tag == "-" {
+				skipped = append(skipped, sf.Index)
+			}
+			continue
+		}
+		if !sf.IsExported() || tag == "-" {
+			continue
+		}
+		fields = append(fields, parseObjectTag(sf, tag))
+	}
+	cached, _ := objectFieldsCache.LoadOrStore(t, fields)
+	return cached.([]objectField)
+}
+
+// isPromotedFrom reports whether the field of the index is promoted from one of the embedded fields.
+func isPromotedFrom(index []int, embedded [][]int) bool {
+	for _, e := range embedded {
+		if len(index) > len(e) && slices.Equal(index[:len(e)], e) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseObjectTag(sf reflect.StructField, tag string) objectField {
+	f := objectField{index: sf.Index, key: sf.Name}
+	if name, _, _ := strings.Cut(sf.Tag.Get("json"), ","); name != "" && name != "-" {
+		f.key = name
+	}
+	for _, opt := range strings.Split(tag, ",") {
+		switch opt = strings.TrimSpace(opt); {
+		case opt == "mask":
+			f.mask = true
+		case opt == "hash":
+			f.hash = true
+		case opt == "console":
+			f.console = true
+		case strings.HasPrefix(opt, "name="):
+			if name := strings.TrimPrefix(opt, "name="); name != "" {
+				f.key = name
+			}
+		}
+	}
+	return f
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (o *objectMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for i := range o.fields {
+		v, ok := o.field(&o.fields[i])
+		if !ok {
+			continue
+		}
+		if err := addObjectValue(enc, &o.fields[i], v, o.depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// field returns the value of f. ok is false if f is promoted through a nil embedded pointer.
+func (o *objectMarshaler) field(f *objectField) (reflect.Value, bool) {
+	v, err := o.val.FieldByIndexErr(f.index)
+	return v, err == nil
+}
+
+// consoleValues returns the values of the fields tagged with `zl:"console"`.
+func (o *objectMarshaler) consoleValues() []string {
+	var ret []string
+	for i := range o.fields {
+		if !o.fields[i].console {
+			continue
+		}
+		if v, ok := o.field(&o.fields[i]); ok {
+			ret = append(ret, o.fields[i].format(v))
+		}
+	}
+	return ret
+}
+
+func (f *objectField) format(v reflect.Value) string {
+	switch {
+	case f.mask:
+		return MaskedValue
+	case f.hash:
+		return hashValue(v)
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "<nil>"
+		}
+		v = v.Elem()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func hashValue(v reflect.Value) string {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			break
+		}
+		v = v.Elem()
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprint(v.Interface())))
+	return hex.EncodeToString(sum[:])
+}
+
+// addObjectValue adds the value of the field. The structs nested deeper than maxObjectDepth are not written,
+// and the error is written as the "<key>Error" field by zap like other marshaling errors.
+// nolint:funlen
+func addObjectValue(enc zapcore.ObjectEncoder, f *objectField, v reflect.Value, depth int) error {
+	key := f.key
+	if f.mask || f.hash {
+		enc.AddString(key, f.format(v))
+		return nil
+	}
+	if v.Type().Implements(errorType) && !(v.Kind() == reflect.Pointer && v.IsNil()) {
+		if err, ok := v.Interface().(error); ok && err != nil {
+			enc.AddString(key, err.Error())
+			return nil
+		}
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return enc.AddReflected(key, nil)
+		}
+		v = v.Elem()
+	}
+	switch v.Type() {
+	case timeType:
+		enc.AddTime(key, v.Interface().(time.Time))
+		return nil
+	case durationType:
+		enc.AddDuration(key, time.Duration(v.Int()))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		enc.AddString(key, v.String())
+	case reflect.Bool:
+		enc.AddBool(key, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		enc.AddInt64(key, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		enc.AddUint64(key, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		enc.AddFloat64(key, v.Float())
+	case reflect.Struct:
+		if depth >= maxObjectDepth {
+			return errObjectTooDeep
+		}
+		return enc.AddObject(key, newObjectMarshaler(v, depth+1))
+	case reflect.Slice, reflect.Array:
+		if indirectType(v.Type().Elem()).Kind() == reflect.Struct {
+			if depth >= maxObjectDepth {
+				return errObjectTooDeep
+			}
+			return enc.AddArray(key, objectArrayMarshaler{val: v, depth: depth + 1})
+		}
+		return enc.AddReflected(key, v.Interface())
+	default:
+		return enc.AddReflected(key, v.Interface())
+	}
+	return nil
+}
+
+// objectArrayMarshaler marshals a slice of structs so that the zl tags of each element are honored.
+type objectArrayMarshaler struct {
+	val   reflect.Value
+	depth int
+}
+
+// MarshalLogArray implements zapcore.ArrayMarshaler.
+func (a objectArrayMarshaler) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for i := 0; i < a.val.Len(); i++ {
+		if err := enc.AppendObject(newObjectMarshaler(a.val.Index(i), a.depth)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
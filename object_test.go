@@ -0,0 +1,144 @@
+package zl
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type testObjectAddress struct {
+	City string `json:"city"`
+	Zip  string `zl:"mask"`
+}
+
+type testObjectUser struct {
+	ID        int    `zl:"name=user_id,console"`
+	Name      string `json:"name,omitempty" zl:"console"`
+	Email     string `zl:"hash"`
+	Password  string `zl:"-"`
+	Token     string `zl:"mask"`
+	Err       error
+	Elapsed   time.Duration
+	Address   *testObjectAddress
+	Addresses []testObjectAddress
+	Tags      []string
+	private   string
+}
+
+func TestObject(t *testing.T) {
+	user := &testObjectUser{
+		ID:        1,
+		Name:      "Alice",
+		Email:     "alice@example.com",
+		Password:  "secret",
+		Token:     "token",
+		Err:       errors.New("some error"),
+		Elapsed:   time.Second,
+		Address:   &testObjectAddress{City: "Tokyo", Zip: "100-0001"},
+		Addresses: []testObjectAddress{{City: "Osaka", Zip: "530-0001"}},
+		Tags:      []string{"a", "b"},
+		private:   "private",
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	Object(user).AddTo(enc)
+
+	assert.Equal(t, map[string]interface{}{
+		"user_id": int64(1),
+		"name":    "Alice",
+		"Email":   "ff8d9819fc0e12bf0d24892e45987e249a28dce836a85cad60e28eaaa8c6d976",
+		"Token":   MaskedValue,
+		"Err":     "some error",
+		"Elapsed": time.Second,
+		"Address": map[string]interface{}{"city": "Tokyo", "Zip": MaskedValue},
+		"Addresses": []interface{}{
+			map[string]interface{}{"city": "Osaka", "Zip": MaskedValue},
+		},
+		"Tags": []string{"a", "b"},
+	}, enc.Fields)
+}
+
+func TestNamedObject(t *testing.T) {
+	enc := zapcore.NewMapObjectEncoder()
+	NamedObject("address", testObjectAddress{City: "Tokyo", Zip: "100-0001"}).AddTo(enc)
+	assert.Equal(t, map[string]interface{}{
+		"address": map[string]interface{}{"city": "Tokyo", "Zip": MaskedValue},
+	}, enc.Fields)
+
+	t.Run("not struct", func(t *testing.T) {
+		enc := zapcore.NewMapObjectEncoder()
+		NamedObject("nil", (*testObjectAddress)(nil)).AddTo(enc)
+		NamedObject("string", "abc").AddTo(enc)
+		assert.Equal(t, map[string]interface{}{
+			"nil":    map[string]interface{}{},
+			"string": map[string]interface{}{},
+		}, enc.Fields)
+	})
+}
+
+func Test_getObjectFields(t *testing.T) {
+	typ := reflect.TypeOf(testObjectUser{})
+	fields := getObjectFields(typ)
+	assert.Equal(t, objectField{index: []int{0}, key: "user_id", console: true}, fields[0])
+	assert.Len(t, fields, 9)
+
+	cached, ok := objectFieldsCache.Load(typ)
+	assert.True(t, ok)
+	assert.Equal(t, fields, cached)
+}
+
+type testObjectSecret struct {
+	Password string
+	Nested   struct{ Key string }
+}
+
+type testObjectCredential struct {
+	testObjectSecret `zl:"-"`
+	*testObjectAddress
+	Name string
+}
+
+func TestObject_embeddedSkipped(t *testing.T) {
+	enc := zapcore.NewMapObjectEncoder()
+	Object(testObjectCredential{
+		testObjectSecret:  testObjectSecret{Password: "secret"},
+		testObjectAddress: &testObjectAddress{City: "Tokyo", Zip: "100-0001"},
+		Name:              "Alice",
+	}).AddTo(enc)
+	assert.Equal(t, map[string]interface{}{
+		"city": "Tokyo",
+		"Zip":  MaskedValue,
+		"Name": "Alice",
+	}, enc.Fields)
+}
+
+type testObjectNode struct {
+	Name string
+	Next *testObjectNode
+}
+
+func TestNamedObject_cycle(t *testing.T) {
+	node := &testObjectNode{Name: "a"}
+	node.Next = node
+	enc := zapcore.NewMapObjectEncoder()
+	NamedObject("node", node).AddTo(enc)
+	assert.Equal(t, "the object is nested deeper than 32 levels, or has a cyclic reference", enc.Fields["nodeError"])
+	assert.Equal(t, "a", enc.Fields["node"].(map[string]interface{})["Name"])
+}
+
+func Test_prettyLogger_consoleMsg_object(t *testing.T) {
+	var buf bytes.Buffer
+	l := newPrettyLogger(&buf, os.Stderr)
+
+	expected := separator + "\u001B[36m1\u001B[0m" + separator + "\u001B[36mAlice\u001B[0m"
+	actual := l.consoleMsg([]zap.Field{
+		Object(testObjectUser{ID: 1, Name: "Alice", Token: "token"}),
+	})
+	assert.Equal(t, expected, actual)
+}
@@ -64,12 +64,21 @@ type ErrorLog struct {
 	Line       int
 }
 
+// groupKey returns the key to group the same errors.
+func (e *ErrorLog) groupKey() string {
+	return fmt.Sprintf("severity:%s,message:%s,caller:%s,error:%s",
+		e.Severity, e.Message, e.Caller, e.Error,
+	)
+}
+
 const (
-	DebugLevel = zapcore.DebugLevel
-	InfoLevel  = zapcore.InfoLevel
-	WarnLevel  = zapcore.WarnLevel
-	ErrorLevel = zapcore.ErrorLevel
-	FatalLevel = zapcore.FatalLevel
+	DebugLevel  = zapcore.DebugLevel
+	InfoLevel   = zapcore.InfoLevel
+	WarnLevel   = zapcore.WarnLevel
+	ErrorLevel  = zapcore.ErrorLevel
+	DPanicLevel = zapcore.DPanicLevel
+	PanicLevel  = zapcore.PanicLevel
+	FatalLevel  = zapcore.FatalLevel
 )
 
 // Output is log output type.
@@ -110,50 +119,84 @@ func (o Output) String() string {
 // SetOutput is set Output type.
 // option can use (PrettyOutput, ConsoleAndFileOutput, ConsoleOutput, FileOutput).
 func SetOutput(option Output) {
+	mu.Lock()
+	defer mu.Unlock()
 	outputType = option
 }
 
 // SetOutputByString is set Output type by string.
 // outputTypeStr can use (Pretty, ConsoleAndFile, Console, File).
 func SetOutputByString(outputTypeStr string) {
+	output, err := parseOutput(outputTypeStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	SetOutput(output)
+}
+
+func parseOutput(outputTypeStr string) (Output, error) {
 	var output Output
 	if outputTypeStr == "" {
-		SetOutput(output)
-		return
+		return output, nil
 	}
 	for i, i2 := range outputStrings {
 		if outputTypeStr == i2 {
-			SetOutput(Output(i))
-			return
+			return Output(i), nil
 		}
 	}
-	log.Fatalf(
+	return output, fmt.Errorf(
 		"%s is invalid type. can use (Pretty, ConsoleAndFile, Console, File)",
 		outputTypeStr,
 	)
 }
 
 // SetLevel is set log.
-// level can use (DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel).
+// level can use (DebugLevel, InfoLevel, WarnLevel, ErrorLevel, DPanicLevel, PanicLevel, FatalLevel).
 func SetLevel(level zapcore.Level) {
+	mu.Lock()
+	defer mu.Unlock()
 	severityLevel = level
 }
 
 // SetLevelByString is set log level.
-// levelStr can use (DEBUG, INFO, WARN, ERROR, FATAL).
+// levelStr can use (DEBUG, INFO, WARN, ERROR, DPANIC, PANIC, FATAL).
 func SetLevelByString(levelStr string) {
-	var level zapcore.Level
-	err := level.UnmarshalText([]byte(levelStr))
+	level, err := parseLevel(levelStr)
 	if err != nil {
-		log.Fatalf("%s is invalid level. can use (DEBUG, INFO, WARN, ERROR, FATAL)", levelStr)
+		log.Fatal(err)
 	}
 	SetLevel(level)
 }
 
+func parseLevel(levelStr string) (zapcore.Level, error) {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(levelStr)); err != nil {
+		return level, fmt.Errorf("%s is invalid level. can use (DEBUG, INFO, WARN, ERROR, DPANIC, PANIC, FATAL)", levelStr)
+	}
+	return level, nil
+}
+
+// SetDevelopment sets the development mode, in which DPanic and DPanicErr panic after writing the log.
+// By default, it is enabled when the output type is PrettyOutput.
+func SetDevelopment(enable bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	development = &enable
+}
+
+func isDevelopment() bool {
+	if development != nil {
+		return *development
+	}
+	return outputType == PrettyOutput
+}
+
 // SetRepositoryCallerEncoder is set CallerEncoder.
 // It set caller's source code's URL of the Repository that called.
 // It is used in the log output CallerKey field.
 func SetRepositoryCallerEncoder(urlFormat, revisionOrTag, srcRootDir string) {
+	mu.Lock()
+	defer mu.Unlock()
 	if revisionOrTag == "" || srcRootDir == "" {
 		return
 	}
@@ -173,16 +216,32 @@ func buildRepositoryCallerEncoder(dir, url string) zapcore.CallerEncoder {
 // It set version of the application.
 // It is used in the log output VersionKey field.
 func SetVersion(revisionOrTag string) {
+	mu.Lock()
+	defer mu.Unlock()
 	version = revisionOrTag
 }
 
+// SetServiceName sets the name of the application.
+// It is the default name of the application in the sinks created after it,
+// e.g. the APP-NAME of SyslogWriter or the service.name of OTLPWriter.
+// The name of the executable is used if it is not set.
+func SetServiceName(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	serviceName = name
+}
+
 // SetConsoleFields add the fields to be displayed in the console when PrettyOutput is used.
 func SetConsoleFields(fieldKey ...string) {
+	mu.Lock()
+	defer mu.Unlock()
 	consoleFields = append(consoleFields, fieldKey...)
 }
 
 // SetOmitKeys set fields to omit from default fields that used in each log.
 func SetOmitKeys(key ...Key) {
+	mu.Lock()
+	defer mu.Unlock()
 	omitKeys = key
 }
 
@@ -191,15 +250,29 @@ func SetFieldKey(key Key, val string) {
 	if key == "" || val == "" {
 		return
 	}
-	fieldKeys[key] = val
+	mu.Lock()
+	defer mu.Unlock()
+	m := map[Key]string{key: val}
+	if old := fieldKeys.Load(); old != nil {
+		for k, v := range *old {
+			if k != key {
+				m[k] = v
+			}
+		}
+	}
+	fieldKeys.Store(&m)
 }
 
 // SetStdout is changes the console log output from stderr to stdout.
 func SetStdout() {
+	mu.Lock()
+	defer mu.Unlock()
 	isStdOut = true
 }
 
 // SetSeparator is changes the console log output separator when PrettyOutput is used.
 func SetSeparator(val string) {
+	mu.Lock()
+	defer mu.Unlock()
 	separator = val
 }
@@ -1,8 +1,12 @@
 package zl
 
 import (
+	"errors"
+	"os"
+	"strings"
 	"testing"
 
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap/zapcore"
 )
@@ -37,7 +41,7 @@ func TestSetOutputByString(t *testing.T) {
 }
 
 func TestSetLevel(t *testing.T) {
-	tests := []zapcore.Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel}
+	tests := []zapcore.Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, DPanicLevel, PanicLevel, FatalLevel}
 	for _, tt := range tests {
 		t.Run(tt.String(), func(t *testing.T) {
 			SetLevel(tt)
@@ -56,6 +60,8 @@ func TestSetLevelByString(t *testing.T) {
 		{"info", InfoLevel},
 		{"warn", WarnLevel},
 		{"error", ErrorLevel},
+		{"dpanic", DPanicLevel},
+		{"panic", PanicLevel},
 		{"fatal", FatalLevel},
 	}
 	for _, tt := range tests {
@@ -67,6 +73,53 @@ func TestSetLevelByString(t *testing.T) {
 	}
 }
 
+func TestSetDevelopment(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      Output
+		development *bool
+		panics      bool
+	}{
+		{"Pretty", PrettyOutput, nil, true},
+		{"PrettyDisabled", PrettyOutput, lo.ToPtr(false), false},
+		{"File", FileOutput, nil, false},
+		{"FileEnabled", FileOutput, lo.ToPtr(true), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := t.TempDir() + "/app.jsonl"
+			SetOutput(tt.output)
+			SetRotateFileName(fileName)
+			if tt.development != nil {
+				SetDevelopment(*tt.development)
+			}
+			defer ResetGlobalLoggerSettings()
+			Init()
+
+			if tt.panics {
+				assert.PanicsWithValue(t, "DPANIC_MESSAGE", func() {
+					DPanicErr("DPANIC_MESSAGE", errors.New("some error"))
+				})
+			} else {
+				assert.NotPanics(t, func() {
+					DPanicErr("DPANIC_MESSAGE", errors.New("some error"))
+				})
+			}
+			assert.PanicsWithValue(t, "PANIC_MESSAGE", func() {
+				New().Panic("PANIC_MESSAGE")
+			})
+
+			b, err := os.ReadFile(fileName)
+			assert.NoError(t, err)
+			lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+			assert.Contains(t, lines[len(lines)-2], `"severity":"DPANIC"`)
+			assert.Contains(t, lines[len(lines)-2], `"caller":"zl/options_test.go:`)
+			assert.Contains(t, lines[len(lines)-2], `"stacktrace":`)
+			assert.Contains(t, lines[len(lines)-1], `"severity":"PANIC"`)
+		})
+	}
+}
+
 func TestSetFieldKey(t *testing.T) {
 	SetFieldKey(FunctionKey, "func")
 	SetFieldKey(StacktraceKey, "stack_trace")
@@ -77,7 +130,7 @@ func TestSetFieldKey(t *testing.T) {
 			FunctionKey:   "func",
 			StacktraceKey: "stack_trace",
 		},
-		fieldKeys,
+		*fieldKeys.Load(),
 	)
 	ResetGlobalLoggerSettings()
 }
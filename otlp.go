@@ -0,0 +1,453 @@
+package zl
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// OTLPEncoding is the encoding of the OTLP/HTTP requests.
+type OTLPEncoding int
+
+const (
+	// OTLPProtobuf sends the requests as binary protobuf. It is Default setting.
+	OTLPProtobuf OTLPEncoding = iota
+	// OTLPJSON sends the requests as JSON.
+	OTLPJSON
+)
+
+const otlpScopeName = "github.com/nkmr-jp/zl"
+
+// OTLPOptions is the settings of the OTLP sink.
+type OTLPOptions struct {
+	// Endpoint is the URL of the logs endpoint. Default is "http://localhost:4318/v1/logs".
+	Endpoint string
+	// Encoding is the encoding of the requests. Default is OTLPProtobuf.
+	Encoding OTLPEncoding
+	// Headers are added to the requests. e.g. authorization headers.
+	Headers map[string]string
+	// ServiceName is the service.name resource attribute. Default is the name set by SetServiceName or the name of the executable.
+	ServiceName string
+	// Timeout is the timeout of a request. Default is 10 seconds.
+	Timeout time.Duration
+	// Batch is the settings of batching.
+	Batch BatchOptions
+}
+
+// OTLPWriter is a zapcore.WriteSyncer that exports the entries encoded by JSONEncoder
+// to an OpenTelemetry Collector as OTLP LogRecords over HTTP.
+// The message is the body, the version, hostname and pid fields are the resource attributes,
+// the trace_id, span_id and trace_flags fields are the trace context, and the other fields are the attributes.
+// See: https://opentelemetry.io/docs/specs/otlp/#otlphttp
+//
+// A typical usage would be something like.
+//
+//	w := zl.NewOTLPWriter(zl.OTLPOptions{Endpoint: "http://localhost:4318/v1/logs"})
+//	zl.AddSink("otlp", w, zl.SinkOptions{Level: zl.InfoLevel})
+//	zl.Init()
+//	defer w.Close()
+type OTLPWriter struct {
+	*batchWriter
+	options OTLPOptions
+	client  *http.Client
+}
+
+// NewOTLPWriter returns the OTLPWriter.
+func NewOTLPWriter(options OTLPOptions) *OTLPWriter {
+	if options.Endpoint == "" {
+		options.Endpoint = "http://localhost:4318/v1/logs"
+	}
+	if options.ServiceName == "" {
+		options.ServiceName = appName()
+	}
+	if options.Timeout == 0 {
+		options.Timeout = 10 * time.Second
+	}
+	w := &OTLPWriter{options: options, client: &http.Client{Timeout: options.Timeout}}
+	w.batchWriter = newBatchWriter(options.Batch, dropNonJSON(w.send))
+	return w
+}
+
+// requiresJSON implements jsonSink.
+func (w *OTLPWriter) requiresJSON() {}
+
+func (w *OTLPWriter) send(entries [][]byte) error {
+	var body []byte
+	contentType := "application/x-protobuf"
+	if w.options.Encoding == OTLPJSON {
+		b, err := json.Marshal(w.request(entries).jsonValue())
+		if err != nil {
+			return &permanentError{err: err}
+		}
+		body, contentType = b, "application/json"
+	} else {
+		body = w.request(entries).protobuf()
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, w.options.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return &permanentError{err: err}
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range w.options.Headers {
+		req.Header.Set(k, v)
+	}
+	return postRequest(w.client, req)
+}
+
+// otlpRequest is an ExportLogsServiceRequest.
+type otlpRequest struct {
+	resourceLogs []*otlpResourceLogs
+}
+
+type otlpResourceLogs struct {
+	resource []otlpKeyValue
+	records  []*otlpRecord
+}
+
+type otlpRecord struct {
+	timeUnixNano         uint64
+	observedTimeUnixNano uint64
+	severityNumber       int
+	severityText         string
+	body                 string
+	attributes           []otlpKeyValue
+	traceID              []byte
+	spanID               []byte
+	flags                uint32
+}
+
+type otlpKeyValue struct {
+	key   string
+	value interface{}
+}
+
+// request converts the entries to the request. The entries with the same resource are grouped.
+func (w *OTLPWriter) request(entries [][]byte) *otlpRequest {
+	req := &otlpRequest{}
+	groups := make(map[string]*otlpResourceLogs)
+	for _, p := range entries {
+		m, err := parseEntry(p)
+		if err != nil {
+			continue
+		}
+		resource := w.resource(m)
+		key := fmt.Sprint(resource)
+		if groups[key] == nil {
+			groups[key] = &otlpResourceLogs{resource: resource}
+			req.resourceLogs = append(req.resourceLogs, groups[key])
+		}
+		groups[key].records = append(groups[key].records, newOTLPRecord(m))
+	}
+	return req
+}
+
+// resource returns the resource attributes and removes the fields from the entry.
+func (w *OTLPWriter) resource(m map[string]interface{}) []otlpKeyValue {
+	attrs := []otlpKeyValue{{"service.name", w.options.ServiceName}}
+	for key, name := range map[Key]string{
+		VersionKey:  "service.version",
+		HostnameKey: "host.name",
+		PIDKey:      "process.pid",
+	} {
+		if v, ok := m[fieldKey(key)]; ok {
+			attrs = append(attrs, otlpKeyValue{name, v})
+			delete(m, fieldKey(key))
+		}
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].key < attrs[j].key })
+	return attrs
+}
+
+func newOTLPRecord(m map[string]interface{}) *otlpRecord {
+	level := entryLevel(m)
+	r := &otlpRecord{
+		timeUnixNano:         uint64(entryTime(m).UnixNano()),
+		observedTimeUnixNano: uint64(currentTime().UnixNano()),
+		severityNumber:       otlpSeverityNumber(level),
+		severityText:         level.CapitalString(),
+		body:                 entryString(m, MessageKey),
+	}
+	if id, err := hex.DecodeString(stringField(m, traceIDKey)); err == nil && len(id) == 16 {
+		r.traceID = id
+		delete(m, traceIDKey)
+	}
+	if id, err := hex.DecodeString(stringField(m, spanIDKey)); err == nil && len(id) == 8 {
+		r.spanID = id
+		delete(m, spanIDKey)
+	}
+	if n, ok := m[traceFlagsKey].(json.Number); ok {
+		if flags, err := strconv.ParseUint(n.String(), 10, 8); err == nil {
+			r.flags = uint32(flags)
+			delete(m, traceFlagsKey)
+		}
+	}
+	for _, key := range []Key{MessageKey, LevelKey, TimeKey} {
+		delete(m, fieldKey(key))
+	}
+	r.attributes = otlpKeyValues(m)
+	return r
+}
+
+func stringField(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// otlpSeverityNumber maps the zap level to the OpenTelemetry severity number.
+// See: https://opentelemetry.io/docs/specs/otel/logs/data-model/#field-severitynumber
+func otlpSeverityNumber(level zapcore.Level) int {
+	switch level {
+	case DebugLevel:
+		return 5
+	case InfoLevel:
+		return 9
+	case WarnLevel:
+		return 13
+	case ErrorLevel:
+		return 17
+	case DPanicLevel:
+		return 18
+	case PanicLevel:
+		return 19
+	default:
+		return 21
+	}
+}
+
+func otlpKeyValues(m map[string]interface{}) []otlpKeyValue {
+	kvs := make([]otlpKeyValue, 0, len(m))
+	for k, v := range m {
+		kvs = append(kvs, otlpKeyValue{k, v})
+	}
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].key < kvs[j].key })
+	return kvs
+}
+
+// jsonValue returns the request in the OTLP/JSON format.
+// See: https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+func (r *otlpRequest) jsonValue() interface{} {
+	resourceLogs := make([]interface{}, 0, len(r.resourceLogs))
+	for _, rl := range r.resourceLogs {
+		records := make([]interface{}, 0, len(rl.records))
+		for _, rec := range rl.records {
+			record := map[string]interface{}{
+				"timeUnixNano":         strconv.FormatUint(rec.timeUnixNano, 10),
+				"observedTimeUnixNano": strconv.FormatUint(rec.observedTimeUnixNano, 10),
+				"severityNumber":       rec.severityNumber,
+				"severityText":         rec.severityText,
+				"body":                 otlpJSONValue(rec.body),
+				"attributes":           otlpJSONKeyValues(rec.attributes),
+			}
+			if rec.traceID != nil {
+				record["traceId"] = hex.EncodeToString(rec.traceID)
+			}
+			if rec.spanID != nil {
+				record["spanId"] = hex.EncodeToString(rec.spanID)
+			}
+			if rec.flags != 0 {
+				record["flags"] = rec.flags
+			}
+			records = append(records, record)
+		}
+		resourceLogs = append(resourceLogs, map[string]interface{}{
+			"resource": map[string]interface{}{"attributes": otlpJSONKeyValues(rl.resource)},
+			"scopeLogs": []interface{}{map[string]interface{}{
+				"scope":      map[string]interface{}{"name": otlpScopeName},
+				"logRecords": records,
+			}},
+		})
+	}
+	return map[string]interface{}{"resourceLogs": resourceLogs}
+}
+
+func otlpJSONKeyValues(kvs []otlpKeyValue) []interface{} {
+	values := make([]interface{}, 0, len(kvs))
+	for _, kv := range kvs {
+		values = append(values, map[string]interface{}{"key": kv.key, "value": otlpJSONValue(kv.value)})
+	}
+	return values
+}
+
+// otlpJSONValue returns the AnyValue. 64 bit integers are encoded as strings.
+func otlpJSONValue(val interface{}) map[string]interface{} {
+	switch v := val.(type) {
+	case string:
+		return map[string]interface{}{"stringValue": v}
+	case bool:
+		return map[string]interface{}{"boolValue": v}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return map[string]interface{}{"intValue": strconv.FormatInt(i, 10)}
+		}
+		f, _ := v.Float64()
+		return map[string]interface{}{"doubleValue": f}
+	case []interface{}:
+		values := make([]interface{}, 0, len(v))
+		for i := range v {
+			values = append(values, otlpJSONValue(v[i]))
+		}
+		return map[string]interface{}{"arrayValue": map[string]interface{}{"values": values}}
+	case map[string]interface{}:
+		return map[string]interface{}{"kvlistValue": map[string]interface{}{"values": otlpJSONKeyValues(otlpKeyValues(v))}}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// protobuf returns the request encoded in the protobuf wire format.
+// See: https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/logs/v1/logs.proto
+func (r *otlpRequest) protobuf() []byte {
+	var req protoBuffer
+	for _, rl := range r.resourceLogs {
+		var resource protoBuffer
+		for _, kv := range rl.resource {
+			resource.message(1, otlpProtoKeyValue(kv))
+		}
+		var scopeLogs protoBuffer
+		var scope protoBuffer
+		scope.string(1, otlpScopeName)
+		scopeLogs.message(1, scope)
+		for _, rec := range rl.records {
+			scopeLogs.message(2, rec.protobuf())
+		}
+		var resourceLogs protoBuffer
+		resourceLogs.message(1, resource)
+		resourceLogs.message(2, scopeLogs)
+		req.message(1, resourceLogs)
+	}
+	return req
+}
+
+func (r *otlpRecord) protobuf() protoBuffer {
+	var b protoBuffer
+	b.fixed64(1, r.timeUnixNano)
+	b.varint(2, uint64(r.severityNumber))
+	b.string(3, r.severityText)
+	b.message(5, otlpProtoValue(r.body))
+	for _, kv := range r.attributes {
+		b.message(6, otlpProtoKeyValue(kv))
+	}
+	if r.flags != 0 {
+		b.fixed32(8, r.flags)
+	}
+	b.bytes(9, r.traceID)
+	b.bytes(10, r.spanID)
+	b.fixed64(11, r.observedTimeUnixNano)
+	return b
+}
+
+func otlpProtoKeyValue(kv otlpKeyValue) protoBuffer {
+	var b protoBuffer
+	b.string(1, kv.key)
+	b.message(2, otlpProtoValue(kv.value))
+	return b
+}
+
+func otlpProtoValue(val interface{}) protoBuffer {
+	var b protoBuffer
+	switch v := val.(type) {
+	case string:
+		b.tag(1, 2)
+		b.appendBytes([]byte(v))
+	case bool:
+		b.tag(2, 0)
+		if v {
+			b.appendVarint(1)
+		} else {
+			b.appendVarint(0)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			b.tag(3, 0)
+			b.appendVarint(uint64(i))
+		} else {
+			f, _ := v.Float64()
+			b.tag(4, 1)
+			b.appendFixed64(math.Float64bits(f))
+		}
+	case []interface{}:
+		var arr protoBuffer
+		for i := range v {
+			arr.message(1, otlpProtoValue(v[i]))
+		}
+		b.message(5, arr)
+	case map[string]interface{}:
+		var list protoBuffer
+		for _, kv := range otlpKeyValues(v) {
+			list.message(1, otlpProtoKeyValue(kv))
+		}
+		b.message(6, list)
+	}
+	return b
+}
+
+// protoBuffer is a minimal encoder of the protobuf wire format.
+// Empty values are omitted as the proto3 default values.
+// See: https://protobuf.dev/programming-guides/encoding/
+type protoBuffer []byte
+
+func (b *protoBuffer) tag(num, wireType int) {
+	b.appendVarint(uint64(num)<<3 | uint64(wireType))
+}
+
+func (b *protoBuffer) appendVarint(v uint64) {
+	*b = binary.AppendUvarint(*b, v)
+}
+
+func (b *protoBuffer) appendFixed64(v uint64) {
+	*b = binary.LittleEndian.AppendUint64(*b, v)
+}
+
+func (b *protoBuffer) appendBytes(v []byte) {
+	b.appendVarint(uint64(len(v)))
+	*b = append(*b, v...)
+}
+
+func (b *protoBuffer) varint(num int, v uint64) {
+	if v != 0 {
+		b.tag(num, 0)
+		b.appendVarint(v)
+	}
+}
+
+func (b *protoBuffer) fixed32(num int, v uint32) {
+	if v != 0 {
+		b.tag(num, 5)
+		*b = binary.LittleEndian.AppendUint32(*b, v)
+	}
+}
+
+func (b *protoBuffer) fixed64(num int, v uint64) {
+	if v != 0 {
+		b.tag(num, 1)
+		b.appendFixed64(v)
+	}
+}
+
+func (b *protoBuffer) string(num int, v string) {
+	b.bytes(num, []byte(v))
+}
+
+func (b *protoBuffer) bytes(num int, v []byte) {
+	if len(v) > 0 {
+		b.tag(num, 2)
+		b.appendBytes(v)
+	}
+}
+
+// message appends the embedded message. An empty message is also appended.
+func (b *protoBuffer) message(num int, v protoBuffer) {
+	b.tag(num, 2)
+	b.appendBytes(v)
+}
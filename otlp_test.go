@@ -0,0 +1,191 @@
+package zl
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestOTLPWriter_json(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		requests <- r
+		bodies <- b
+	}))
+	defer server.Close()
+
+	w := NewOTLPWriter(OTLPOptions{
+		Endpoint:    server.URL + "/v1/logs",
+		Encoding:    OTLPJSON,
+		Headers:     map[string]string{"Authorization": "Bearer token"},
+		ServiceName: "my-service",
+		Batch:       BatchOptions{Interval: time.Hour},
+	})
+	defer w.Close()
+	SetOutput(ConsoleOutput)
+	SetVersion("v1.0.0")
+	SetOmitKeys(CallerKey, FunctionKey, StacktraceKey)
+	AddSink("otlp", w, SinkOptions{})
+	defer ResetGlobalLoggerSettings()
+	Init()
+
+	Warn("WARN_MESSAGE",
+		zap.String("user", "Alice"),
+		zap.Int("count", 1),
+		zap.Float64("ratio", 0.5),
+		zap.Bool("ok", true),
+		zap.Strings("tags", []string{"a"}),
+		zap.String("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"),
+		zap.String("span_id", "00f067aa0ba902b7"),
+		zap.Int("trace_flags", 1),
+	)
+	assert.NoError(t, w.Sync())
+
+	req := <-requests
+	assert.Equal(t, "/v1/logs", req.URL.Path)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+
+	var body struct {
+		ResourceLogs []struct {
+			Resource struct {
+				Attributes []map[string]interface{} `json:"attributes"`
+			} `json:"resource"`
+			ScopeLogs []struct {
+				LogRecords []map[string]interface{} `json:"logRecords"`
+			} `json:"scopeLogs"`
+		} `json:"resourceLogs"`
+	}
+	assert.NoError(t, json.Unmarshal(<-bodies, &body))
+	assert.Len(t, body.ResourceLogs, 1)
+	rl := body.ResourceLogs[0]
+
+	resource := map[string]interface{}{}
+	for _, kv := range rl.Resource.Attributes {
+		resource[kv["key"].(string)] = kv["value"]
+	}
+	assert.Equal(t, map[string]interface{}{"stringValue": "my-service"}, resource["service.name"])
+	assert.Equal(t, map[string]interface{}{"stringValue": "v1.0.0"}, resource["service.version"])
+	assert.Contains(t, resource, "host.name")
+	assert.Contains(t, resource, "process.pid")
+
+	assert.Len(t, rl.ScopeLogs[0].LogRecords, 1)
+	record := rl.ScopeLogs[0].LogRecords[0]
+	assert.Equal(t, float64(13), record["severityNumber"])
+	assert.Equal(t, "WARN", record["severityText"])
+	assert.Equal(t, map[string]interface{}{"stringValue": "WARN_MESSAGE"}, record["body"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["traceId"])
+	assert.Equal(t, "00f067aa0ba902b7", record["spanId"])
+	assert.Equal(t, float64(1), record["flags"])
+	assert.NotEmpty(t, record["timeUnixNano"])
+
+	attrs, err := json.Marshal(record["attributes"])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"key": "count", "value": {"intValue": "1"}},
+		{"key": "ok", "value": {"boolValue": true}},
+		{"key": "ratio", "value": {"doubleValue": 0.5}},
+		{"key": "tags", "value": {"arrayValue": {"values": [{"stringValue": "a"}]}}},
+		{"key": "user", "value": {"stringValue": "Alice"}}
+	]`, string(attrs))
+}
+
+func TestOTLPWriter_protobuf(t *testing.T) {
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		b, _ := io.ReadAll(r.Body)
+		bodies <- b
+	}))
+	defer server.Close()
+
+	w := NewOTLPWriter(OTLPOptions{Endpoint: server.URL, ServiceName: "svc"})
+	defer w.Close()
+	_, err := w.Write([]byte(`{"severity":"ERROR","timestamp":"2026-10-17T12:00:00Z","message":"ERROR_MESSAGE",` +
+		`"pid":123,"score":1.5,"user":{"name":"Alice"}}`))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Sync())
+
+	// ExportLogsServiceRequest.resource_logs
+	resourceLogs := decodeProto(t, <-bodies)[1][0].([]byte)
+	rl := decodeProto(t, resourceLogs)
+	resource := decodeProto(t, rl[1][0].([]byte))
+	assert.Len(t, resource[1], 2)
+	assert.Equal(t, "process.pid", string(decodeProto(t, resource[1][0].([]byte))[1][0].([]byte)))
+	assert.Equal(t, "service.name", string(decodeProto(t, resource[1][1].([]byte))[1][0].([]byte)))
+
+	scopeLogs := decodeProto(t, rl[2][0].([]byte))
+	scope := decodeProto(t, scopeLogs[1][0].([]byte))
+	assert.Equal(t, otlpScopeName, string(scope[1][0].([]byte)))
+
+	record := decodeProto(t, scopeLogs[2][0].([]byte))
+	assert.Equal(t, uint64(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC).UnixNano()), record[1][0])
+	assert.Equal(t, uint64(17), record[2][0])
+	assert.Equal(t, "ERROR", string(record[3][0].([]byte)))
+	body := decodeProto(t, record[5][0].([]byte))
+	assert.Equal(t, "ERROR_MESSAGE", string(body[1][0].([]byte)))
+	assert.Nil(t, record[9])
+	assert.NotNil(t, record[11])
+
+	assert.Len(t, record[6], 2)
+	score := decodeProto(t, record[6][0].([]byte))
+	assert.Equal(t, "score", string(score[1][0].([]byte)))
+	assert.Equal(t, math.Float64bits(1.5), decodeProto(t, score[2][0].([]byte))[4][0])
+	user := decodeProto(t, record[6][1].([]byte))
+	kvlist := decodeProto(t, decodeProto(t, user[2][0].([]byte))[6][0].([]byte))
+	name := decodeProto(t, kvlist[1][0].([]byte))
+	assert.Equal(t, "name", string(name[1][0].([]byte)))
+	assert.Equal(t, "Alice", string(decodeProto(t, name[2][0].([]byte))[1][0].([]byte)))
+}
+
+func Test_otlpSeverityNumber(t *testing.T) {
+	assert.Equal(t, 5, otlpSeverityNumber(DebugLevel))
+	assert.Equal(t, 9, otlpSeverityNumber(InfoLevel))
+	assert.Equal(t, 13, otlpSeverityNumber(WarnLevel))
+	assert.Equal(t, 17, otlpSeverityNumber(ErrorLevel))
+	assert.Equal(t, 21, otlpSeverityNumber(FatalLevel))
+}
+
+// decodeProto decodes the protobuf message into the values of each field number.
+// The values are uint64 for the numeric wire types and []byte for the length-delimited one.
+func decodeProto(t *testing.T, b []byte) map[int][]interface{} {
+	t.Helper()
+	fields := make(map[int][]interface{})
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if !assert.Greater(t, n, 0) {
+			return fields
+		}
+		b = b[n:]
+		num := int(tag >> 3)
+		switch tag & 7 {
+		case 0:
+			v, n := binary.Uvarint(b)
+			fields[num] = append(fields[num], v)
+			b = b[n:]
+		case 1:
+			fields[num] = append(fields[num], binary.LittleEndian.Uint64(b))
+			b = b[8:]
+		case 2:
+			l, n := binary.Uvarint(b)
+			fields[num] = append(fields[num], b[n:n+int(l)])
+			b = b[n+int(l):]
+		case 5:
+			fields[num] = append(fields[num], uint64(binary.LittleEndian.Uint32(b)))
+			b = b[4:]
+		default:
+			t.Fatalf("unknown wire type: %d", tag&7)
+		}
+	}
+	return fields
+}
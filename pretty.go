@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/davecgh/go-spew/spew"
 	au "github.com/logrusorgru/aurora/v4"
@@ -25,8 +26,26 @@ import (
 type prettyLogger struct {
 	Logger      *log.Logger // Logger is used to output colored logs.
 	internalLog *log.Logger // internalLog is used to output internal errors.
+	callerSkip  int         // callerSkip is the additional number of callers to skip. See: Logger.WithCallerSkip
+	prettySettings
+	derived sync.Map // derived caches the prettyLoggers of the named Loggers. See: derive
 }
 
+// prettySettings is the settings of the console, copied when the prettyLogger is built.
+type prettySettings struct {
+	level         zapcore.Level
+	consoleFields []string
+	separator     string
+	omitReport    bool
+}
+
+// prettyKey is the key of the derived prettyLoggers.
+type prettyKey struct {
+	name       string
+	callerSkip int
+}
+
+// newPrettyLogger returns the prettyLogger, or nil if the output type is not PrettyOutput. mu must be held.
 func newPrettyLogger(out, err io.Writer) *prettyLogger {
 	if outputType != PrettyOutput {
 		return nil
@@ -38,14 +57,43 @@ func newPrettyLogger(out, err io.Writer) *prettyLogger {
 	return &prettyLogger{
 		Logger:      l,
 		internalLog: log.New(err, "[INTERNAL ERROR] ", log.Ldate|log.Ltime|log.Lshortfile),
+		prettySettings: prettySettings{
+			level:         severityLevel,
+			consoleFields: consoleFields,
+			separator:     separator,
+			omitReport:    lo.Contains(omitKeys, StacktraceKey) && lo.Contains(omitKeys, PIDKey),
+		},
+	}
+}
+
+// derive returns the prettyLogger for a Logger with the name and the additional caller skip.
+// The name is displayed as the prefix, e.g. "foo.bar | ".
+func (l *prettyLogger) derive(name string, callerSkip int) *prettyLogger {
+	if l == nil || (name == "" && callerSkip == 0) {
+		return l
 	}
+	key := prettyKey{name: name, callerSkip: callerSkip}
+	if d, ok := l.derived.Load(key); ok {
+		return d.(*prettyLogger)
+	}
+	lg := log.New(l.Logger.Writer(), "", l.Logger.Flags())
+	if name != "" {
+		lg.SetPrefix(fmt.Sprintf("%s | ", name))
+	}
+	d, _ := l.derived.LoadOrStore(key, &prettyLogger{
+		Logger:         lg,
+		internalLog:    l.internalLog,
+		callerSkip:     callerSkip,
+		prettySettings: l.prettySettings,
+	})
+	return d.(*prettyLogger)
 }
 
 func (l *prettyLogger) log(msg string, level zapcore.Level, fields []zap.Field) {
-	if outputType != PrettyOutput || level < severityLevel {
+	if l == nil || level < l.level {
 		return
 	}
-	err := l.Logger.Output(4,
+	err := l.Logger.Output(4+l.callerSkip,
 		l.coloredLevel(level).String()+" "+l.coloredMsg(msg, level, fields),
 	)
 	if err != nil {
@@ -54,13 +102,13 @@ func (l *prettyLogger) log(msg string, level zapcore.Level, fields []zap.Field)
 }
 
 func (l *prettyLogger) logWithError(msg string, level zapcore.Level, err error, fields []zap.Field) {
-	if outputType != PrettyOutput || level < severityLevel {
+	if l == nil || level < l.level {
 		return
 	}
 	err2 := l.Logger.Output(
-		4,
+		4+l.callerSkip,
 		l.coloredLevel(level).String()+" "+l.coloredMsg(
-			fmt.Sprintf("%s%s%s", msg, separator, au.Magenta(fmt.Sprintf("%v", err))),
+			fmt.Sprintf("%s%s%s", msg, l.separator, au.Magenta(fmt.Sprintf("%v", err))),
 			level, fields,
 		),
 	)
@@ -84,8 +132,14 @@ func (l *prettyLogger) consoleMsg(fields []zap.Field) string {
 	var ret string
 	var consoles []string
 	for i := range fields {
-		for i2 := range consoleFields {
-			if consoleFields[i2] == fields[i].Key {
+		if obj, ok := fields[i].Interface.(*objectMarshaler); ok {
+			for _, val := range obj.consoleValues() {
+				consoles = append(consoles, au.Cyan(val).String())
+			}
+			continue
+		}
+		for i2 := range l.consoleFields {
+			if l.consoleFields[i2] == fields[i].Key {
 				var val string
 				if fields[i].Type == zapcore.StringType {
 					val = fields[i].String
@@ -101,16 +155,14 @@ func (l *prettyLogger) consoleMsg(fields []zap.Field) string {
 		}
 	}
 	if consoles != nil {
-		ret = separator + strings.Join(consoles, separator)
+		ret = l.separator + strings.Join(consoles, l.separator)
 	}
 	return ret
 }
 
 func (l *prettyLogger) coloredLevel(level zapcore.Level) au.Value {
 	switch level {
-	case FatalLevel:
-		return au.Red(level.CapitalString())
-	case ErrorLevel:
+	case FatalLevel, PanicLevel, DPanicLevel, ErrorLevel:
 		return au.Red(level.CapitalString())
 	case WarnLevel:
 		return au.Yellow(level.CapitalString())
@@ -124,7 +176,7 @@ func (l *prettyLogger) coloredLevel(level zapcore.Level) au.Value {
 
 // showErrorReport writes the colored error report to console.
 func (l *prettyLogger) showErrorReport(fileNameValue string, pidValue int) {
-	if lo.Contains(omitKeys, StacktraceKey) && lo.Contains(omitKeys, PIDKey) {
+	if l.omitReport {
 		return
 	}
 
@@ -170,9 +222,7 @@ func (l *prettyLogger) scanStackTraces(fp *os.File, pidValue int) (int, string,
 		if errorLog.Stacktrace == "" || errorLog.Pid != pidValue {
 			continue
 		}
-		key = fmt.Sprintf("severity:%s,message:%s,caller:%s,error:%s",
-			errorLog.Severity, errorLog.Message, errorLog.Error, errorLog.Caller,
-		)
+		key = errorLog.groupKey()
 		errorLog.Line = ln
 		for i := range groups {
 			if groups[i].Key == key {
@@ -189,7 +239,7 @@ func (l *prettyLogger) scanStackTraces(fp *os.File, pidValue int) (int, string,
 	}
 
 	for i, v := range groups {
-		traces += l.fmtStackTrace(i, len(v.ErrorLogs), v.ErrorLogs[len(v.ErrorLogs)-1])
+		traces += l.fmtStackTrace(fp.Name(), i, len(v.ErrorLogs), v.ErrorLogs[len(v.ErrorLogs)-1])
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -213,22 +263,22 @@ func (l *prettyLogger) printTraces(count int, traces string, pidValue int) error
 	return nil
 }
 
-func (l *prettyLogger) fmtStackTrace(num, count int, el *ErrorLog) string {
+func (l *prettyLogger) fmtStackTrace(logFile string, num, count int, el *ErrorLog) string {
 	var output, logFileAbsPath, errorCount string
-	logFileAbsPath, err := filepath.Abs(fileName)
+	logFileAbsPath, err := filepath.Abs(logFile)
 	if err != nil {
 		return ""
 	}
 
 	if count > 1 {
-		errorCount = au.Faint(fmt.Sprintf("%v(%v times)", separator, count)).String()
+		errorCount = au.Faint(fmt.Sprintf("%v(%v times)", l.separator, count)).String()
 	}
 	output += fmt.Sprintf("%v. %s: %s %s%s%s%v\n",
 		au.Bold(num+1),
 		filepath.Base(el.Caller),
 		l.coloredLevel(el.Severity).String(),
 		el.Message,
-		separator,
+		l.separator,
 		au.Magenta(el.Error),
 		errorCount,
 	)
@@ -253,7 +303,7 @@ func (l *prettyLogger) attr(str string) string {
 }
 
 func (l *prettyLogger) dump(a ...interface{}) {
-	if outputType != PrettyOutput {
+	if l == nil {
 		return
 	}
 	err := l.Logger.Output(3,
@@ -364,8 +364,8 @@ func Test_prettyLogger_showErrorReport(t *testing.T) {
 
 func Test_prettyLogger_consoleMsg(t *testing.T) {
 	var buf bytes.Buffer
-	l := newPrettyLogger(&buf, os.Stderr)
 	consoleFields = []string{"name", "id"}
+	l := newPrettyLogger(&buf, os.Stderr)
 
 	expected := separator + "\u001B[36mAlice\u001B[0m" + separator + "\u001B[34m1\u001B[0m"
 	actual := l.consoleMsg([]zap.Field{
@@ -0,0 +1,97 @@
+package zl
+
+import (
+	"log"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Reconfigure changes the settings with the configure function and rebuilds the loggers at runtime.
+// The entries are written with the new settings after it returns, and the log files
+// opened with the previous settings are closed after the entries being written to them are finished.
+// It initializes the logger if Init has not been called.
+// The Loggers created by New also write to the new outputs, keeping their fields and names.
+//
+// A typical usage would be something like.
+//
+//	zl.Reconfigure(func() {
+//	  zl.SetLevel(zl.DebugLevel)
+//	  zl.SetRotateFileName("./log/debug.jsonl")
+//	})
+func Reconfigure(configure func()) {
+	if configure != nil {
+		configure()
+	}
+
+	mu.Lock()
+	old := loaded.Load()
+	logFile, logFileWriter = nil, nil
+	for _, f := range levelFiles {
+		f.syncer, f.writer = nil, nil
+	}
+	s := newState()
+	loaded.Store(s)
+	mu.Unlock()
+
+	if old != nil {
+		if err := old.close(); err != nil {
+			log.Println(err)
+		}
+	}
+	iDebug("RECONFIGURE_LOGGER", Console(s.String()))
+	s.removeExceedingTotalSize()
+}
+
+// swapCore is a zapcore.Core that writes to the core of the current state,
+// so that the Loggers created by New follow Reconfigure.
+// The fields added by With are encoded once for each state.
+type swapCore struct {
+	fields []zap.Field
+	cache  atomic.Pointer[swapCache]
+}
+
+// swapCache is the core of the state with the fields of swapCore.
+type swapCache struct {
+	state *state
+	core  zapcore.Core
+}
+
+func (c *swapCore) core() zapcore.Core {
+	s := loadState()
+	if cache := c.cache.Load(); cache != nil && cache.state == s {
+		return cache.core
+	}
+	core := s.core
+	if len(c.fields) > 0 {
+		core = core.With(c.fields)
+	}
+	c.cache.Store(&swapCache{state: s, core: core})
+	return core
+}
+
+// Enabled implements zapcore.LevelEnabler.
+func (c *swapCore) Enabled(level zapcore.Level) bool {
+	return c.core().Enabled(level)
+}
+
+// With implements zapcore.Core.
+func (c *swapCore) With(fields []zap.Field) zapcore.Core {
+	return &swapCore{fields: append(c.fields[:len(c.fields):len(c.fields)], fields...)}
+}
+
+// Check implements zapcore.Core.
+func (c *swapCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return c.core().Check(ent, ce)
+}
+
+// Write implements zapcore.Core.
+func (c *swapCore) Write(ent zapcore.Entry, fields []zap.Field) error {
+	return c.core().Write(ent, fields)
+}
+
+// Sync implements zapcore.Core.
+func (c *swapCore) Sync() error {
+	return c.core().Sync()
+}
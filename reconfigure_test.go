@@ -0,0 +1,211 @@
+package zl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestReconfigure(t *testing.T) {
+	dir := t.TempDir()
+	before, after := filepath.Join(dir, "before.jsonl"), filepath.Join(dir, "after.jsonl")
+	SetOutput(FileOutput)
+	SetRotateFileName(before)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, PIDKey)
+	defer ResetGlobalLoggerSettings()
+	Init()
+
+	l := New(zap.String("request_id", "1")).Named("api")
+	Debug("DEBUG_BEFORE")
+	l.Info("INFO_BEFORE")
+
+	Reconfigure(func() {
+		SetLevel(DebugLevel)
+		SetRotateFileName(after)
+	})
+	Debug("DEBUG_AFTER")
+	l.Info("INFO_AFTER")
+	Sync()
+
+	b, err := os.ReadFile(before)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`{"severity":"INFO","logger":"api","message":"INFO_BEFORE","request_id":"1"}`,
+	}, strings.Split(strings.TrimSpace(string(b)), "\n"))
+
+	b, err = os.ReadFile(after)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`{"severity":"DEBUG","message":"RECONFIGURE_LOGGER","console":"Severity: DEBUG, Output: File"}`,
+		`{"severity":"DEBUG","message":"DEBUG_AFTER"}`,
+		`{"severity":"INFO","logger":"api","message":"INFO_AFTER","request_id":"1"}`,
+	}, strings.Split(strings.TrimSpace(string(b)), "\n"))
+}
+
+func TestReconfigure_beforeInit(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "app.jsonl")
+	defer ResetGlobalLoggerSettings()
+	Reconfigure(func() {
+		SetOutput(FileOutput)
+		SetRotateFileName(fileName)
+		SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, PIDKey)
+	})
+	Info("INFO_MESSAGE")
+
+	b, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"severity":"INFO","message":"INFO_MESSAGE"}`, strings.TrimSpace(string(b)))
+}
+
+// TestReconfigure_concurrent is meant to be run with `go test -race`.
+func TestReconfigure_concurrent(t *testing.T) {
+	dir := t.TempDir()
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+	SetBufferedWrite(0, 0)
+	defer ResetGlobalLoggerSettings()
+	Init()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l := New(zap.Int("worker", i)).Named("worker")
+			for j := 0; j < 100; j++ {
+				Info("INFO_MESSAGE")
+				l.Warn("WARN_MESSAGE")
+				l.Sugar().Infof("INFO_MESSAGE %d", j)
+			}
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		Reconfigure(func() {
+			SetLevel(DebugLevel)
+			SetSeparator(":")
+			SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+		})
+	}
+	wg.Wait()
+}
+
+// TestReconfigure_concurrentFiles is meant to be run with `go test -race`.
+// The entries written while the files are switched must not be lost, and the closed files must not be reopened.
+func TestReconfigure_concurrentFiles(t *testing.T) {
+	fds, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skip("/proc/self/fd is not available")
+	}
+	dir := t.TempDir()
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(dir, "app-0.jsonl"))
+	SetBufferedWrite(0, 0)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, PIDKey)
+	defer ResetGlobalLoggerSettings()
+	Init()
+
+	const workers, entries = 8, 1000
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l := New(zap.Int("worker", i))
+			for j := 0; j < entries; j++ {
+				if j%2 == 0 {
+					Info("INFO_MESSAGE")
+				} else {
+					l.Info("INFO_MESSAGE")
+				}
+			}
+		}(i)
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+loop:
+	for i := 1; ; i++ {
+		select {
+		case <-done:
+			break loop
+		default:
+		}
+		Reconfigure(func() {
+			SetRotateFileName(filepath.Join(dir, fmt.Sprintf("app-%d.jsonl", i%3)))
+		})
+	}
+	assert.NoError(t, Close())
+
+	count := 0
+	files, err := filepath.Glob(filepath.Join(dir, "app-*.jsonl"))
+	assert.NoError(t, err)
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		assert.NoError(t, err)
+		count += strings.Count(string(b), `"message":"INFO_MESSAGE"`)
+	}
+	assert.Equal(t, workers*entries, count)
+
+	after, err := os.ReadDir("/proc/self/fd")
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, len(after), len(fds))
+}
+
+func TestReconfigure_hookLogs(t *testing.T) {
+	dir := t.TempDir()
+	entered, release := make(chan struct{}), make(chan struct{})
+	SetOutput(FileOutput)
+	SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, PIDKey)
+	AddHook(func(entry Entry, fields []zap.Field) error {
+		if entry.Message == "OUTER" {
+			// the entry is checked before Reconfigure, and written while it is waiting for the write in progress.
+			ce := logger().Check(InfoLevel, "INNER")
+			close(entered)
+			<-release
+			ce.Write()
+		}
+		return nil
+	})
+	defer ResetGlobalLoggerSettings()
+	Init()
+
+	logged := make(chan struct{})
+	go func() {
+		Info("OUTER")
+		close(logged)
+	}()
+	<-entered
+	reconfigured := make(chan struct{})
+	go func() {
+		Reconfigure(func() {
+			SetRotateFileName(filepath.Join(dir, "after.jsonl"))
+		})
+		close(reconfigured)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	for _, ch := range []chan struct{}{logged, reconfigured} {
+		select {
+		case <-ch:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the log in the hook is deadlocked")
+		}
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "app.jsonl"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"severity":"INFO","message":"OUTER"}`, strings.TrimSpace(string(b)))
+	b, err = os.ReadFile(filepath.Join(dir, "after.jsonl"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"severity":"INFO","message":"INNER"}`, strings.TrimSpace(string(b)))
+}
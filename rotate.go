@@ -1,6 +1,17 @@
 package zl
 
 import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
@@ -9,31 +20,285 @@ const (
 	MaxSizeDefault    = 100 // megabytes
 	MaxBackupsDefault = 3
 	MaxAgeDefault     = 7 // days
+
+	megabyte = 1024 * 1024
 )
 
 var (
-	fileName   string
-	maxSize    int
-	maxBackups int
-	maxAge     int
-	localTime  bool
-	compress   bool
+	fileName       string
+	maxSize        int
+	maxBackups     int
+	maxAge         int
+	localTime      bool
+	compress       bool
+	rotateInterval RotateInterval
+	maxTotalSize   int
+	logFile        rotateSyncer        // logFile is shared by all loggers writing to the log file.
+	logFileWriter  zapcore.WriteSyncer // logFileWriter is logFile with buffering if set.
 )
 
+// rotateSyncer is a zapcore.WriteSyncer that can rotate the log file.
+type rotateSyncer interface {
+	zapcore.WriteSyncer
+	io.Closer
+	Rotate() error
+	removeExceedingTotalSize() error
+}
+
+// sizeRotator adds Sync and the total size limit to lumberjack.Logger.
+type sizeRotator struct {
+	*lumberjack.Logger
+	mu           sync.Mutex
+	file         os.FileInfo // file is the active file after the last write, to detect the rotations by lumberjack.
+	maxTotalSize int64       // bytes
+}
+
+// Write implements io.Writer.
+// When MaxTotalSize is set, it detects the rotations by comparing the active file before and after writing.
+func (r *sizeRotator) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.maxTotalSize <= 0 {
+		return r.Logger.Write(p)
+	}
+	if r.file == nil {
+		r.file, _ = os.Stat(r.Filename)
+	}
+	n, err := r.Logger.Write(p)
+	info, statErr := os.Stat(r.Filename)
+	if statErr != nil {
+		return n, err
+	}
+	rotated := r.file != nil && !os.SameFile(r.file, info)
+	r.file = info
+	if rotated {
+		if err2 := r.removeExceedingTotalSize(); err2 != nil {
+			log.Println(err2)
+		}
+	}
+	return n, err
+}
+
+// Rotate implements rotateSyncer.
+func (r *sizeRotator) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.Logger.Rotate(); err != nil {
+		return err
+	}
+	r.file, _ = os.Stat(r.Filename)
+	return r.removeExceedingTotalSize()
+}
+
+// Sync implements zapcore.WriteSyncer. lumberjack writes to the file without buffering.
+func (r *sizeRotator) Sync() error {
+	return nil
+}
+
+func (r *sizeRotator) removeExceedingTotalSize() error {
+	if r.maxTotalSize <= 0 {
+		return nil
+	}
+	backups, err := listBackups(r.Filename, r.Filename, r.LocalTime, backupTimeFormat)
+	if err != nil {
+		return err
+	}
+	return removeExceedingTotalSize(r.Filename, backups, r.maxTotalSize)
+}
+
+// logBackup is a rotated log file.
+type logBackup struct {
+	path      string
+	timestamp time.Time
+	size      int64
+}
+
+// listBackups returns the rotated files of filename except the active file, newest first.
+// The backups are named the time formatted with one of the layouts between the file name and the extension.
+// e.g. `app-2006-01-02T15-04-05.000.jsonl` or `app-2006-01-02T15-04-05.000.jsonl.gz`
+// A compressed file is skipped while the uncompressed file exists, as it is still being compressed.
+func listBackups(filename, active string, local bool, layouts ...string) ([]logBackup, error) {
+	dir := filepath.Dir(filename)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ext := filepath.Ext(filename)
+	prefix := strings.TrimSuffix(filepath.Base(filename), ext) + "-"
+	names := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		names[e.Name()] = true
+	}
+	var ret []logBackup
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == filepath.Base(active) {
+			continue
+		}
+		if strings.HasSuffix(e.Name(), ".gz") && names[strings.TrimSuffix(e.Name(), ".gz")] {
+			continue
+		}
+		t, ok := parseBackupTime(e.Name(), prefix, ext, local, layouts)
+		if !ok {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, logBackup{path: filepath.Join(dir, e.Name()), timestamp: t, size: info.Size()})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].timestamp.After(ret[j].timestamp)
+	})
+	return ret, nil
+}
+
+func parseBackupTime(name, prefix, ext string, local bool, layouts []string) (time.Time, bool) {
+	name = strings.TrimSuffix(name, ".gz")
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+		return time.Time{}, false
+	}
+	ts := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+	loc := time.UTC
+	if local {
+		loc = time.Local
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, ts, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// removeExceedingTotalSize deletes the oldest backups
+// until the total size of the active file and the backups is within maxBytes.
+func removeExceedingTotalSize(active string, backups []logBackup, maxBytes int64) error {
+	var total int64
+	if info, err := os.Stat(active); err == nil {
+		total = info.Size()
+	}
+	for _, b := range backups {
+		total += b.size
+	}
+	for i := len(backups) - 1; i >= 0 && total > maxBytes; i-- {
+		if err := os.Remove(backups[i].path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= backups[i].size
+	}
+	return nil
+}
+
+// getFileSyncer returns the WriteSyncer of the log file, creating it when first called.
+func getFileSyncer() zapcore.WriteSyncer {
+	if logFile == nil {
+		logFile = newFileSyncer()
+		logFileWriter = withBuffer(logFile)
+	}
+	return logFileWriter
+}
+
+// newFileSyncer returns the WriteSyncer of the log file.
+// It rotates by time when SetRotateInterval is set, otherwise by size.
+func newFileSyncer() rotateSyncer {
+	return getRotateConfig().newSyncer()
+}
+
+// rotateConfig is the rotation settings of a log file.
+type rotateConfig struct {
+	fileName     string
+	maxSize      int
+	maxBackups   int
+	maxAge       int
+	maxTotalSize int
+	localTime    bool
+	compress     bool
+	interval     RotateInterval
+}
+
+// getRotateConfig returns the rotation settings of the main log file.
+func getRotateConfig() rotateConfig {
+	setRotateDefault()
+	return rotateConfig{
+		fileName:     fileName,
+		maxSize:      maxSize,
+		maxBackups:   maxBackups,
+		maxAge:       maxAge,
+		maxTotalSize: maxTotalSize,
+		localTime:    localTime,
+		compress:     compress,
+		interval:     rotateInterval,
+	}
+}
+
+func (c rotateConfig) newSyncer() rotateSyncer {
+	if c.interval != NoInterval {
+		return c.newTimeRotator()
+	}
+	return &sizeRotator{Logger: c.newLumberjack(), maxTotalSize: int64(c.maxTotalSize) * megabyte}
+}
+
+func (c rotateConfig) newLumberjack() *lumberjack.Logger {
+	return &lumberjack.Logger{
+		Filename:   c.fileName,
+		MaxSize:    c.maxSize,
+		MaxBackups: c.maxBackups,
+		MaxAge:     c.maxAge,
+		LocalTime:  c.localTime,
+		Compress:   c.compress,
+	}
+}
+
+// Rotate closes the current log file and opens a new one.
+// With size-based rotation the current file is renamed to a backup first,
+// and with SetRotateInterval the file of the current period is reopened.
+// It is also useful to reopen the file after it was moved by an external tool such as logrotate.
+// The files set by SetFileForLevel are rotated as well.
+func Rotate() error {
+	st := loadState()
+	if len(st.files) == 0 {
+		return errors.New("the log file is not opened. Rotate() is only available when writing to a file")
+	}
+	var errs []error
+	st.use(func() {
+		for _, b := range st.buffers {
+			errs = append(errs, b.Sync())
+		}
+		for _, s := range st.files {
+			errs = append(errs, s.Rotate())
+		}
+	})
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	iDebug("ROTATE_LOG_FILE", Console(st.fileName))
+	return nil
+}
+
+// openedFiles returns the main log file and the level files that have been opened. mu must be held.
+func openedFiles() (ret []rotateSyncer) {
+	if logFile != nil {
+		ret = append(ret, logFile)
+	}
+	for _, f := range levelFiles {
+		if f.syncer != nil {
+			ret = append(ret, f.syncer)
+		}
+	}
+	return ret
+}
+
 // newRotator
 // See: https://github.com/natefinch/lumberjack
 // See: https://github.com/uber-go/zap/blob/master/FAQ.md#does-zap-support-log-rotation
 func newRotator() *lumberjack.Logger {
-	setRotateDefault()
-	res := &lumberjack.Logger{
-		Filename:   fileName,
-		MaxSize:    maxSize,
-		MaxBackups: maxBackups,
-		MaxAge:     maxAge,
-		LocalTime:  localTime,
-		Compress:   compress,
-	}
-	return res
+	return getRotateConfig().newLumberjack()
 }
 
 func setRotateDefault() {
@@ -54,35 +319,66 @@ func setRotateDefault() {
 // SetRotateFileName set the file to write logs to.
 // See: https://github.com/natefinch/lumberjack#type-logger
 func SetRotateFileName(val string) {
+	mu.Lock()
+	defer mu.Unlock()
 	fileName = val
 }
 
 // SetRotateMaxSize set the maximum size in megabytes of the log file before it gets rotated.
 // See: https://github.com/natefinch/lumberjack#type-logger
 func SetRotateMaxSize(val int) {
+	mu.Lock()
+	defer mu.Unlock()
 	maxSize = val
 }
 
 // SetRotateMaxAge set the maximum number of days to retain.
 // See: https://github.com/natefinch/lumberjack#type-logger
 func SetRotateMaxAge(val int) {
+	mu.Lock()
+	defer mu.Unlock()
 	maxAge = val
 }
 
 // SetRotateMaxBackups set the maximum number of old log files to retain.
 // See: https://github.com/natefinch/lumberjack#type-logger
 func SetRotateMaxBackups(val int) {
+	mu.Lock()
+	defer mu.Unlock()
 	maxBackups = val
 }
 
 // SetRotateLocalTime determines if the time used for formatting the timestamps in backup files is the computer's local time.
 // See: https://github.com/natefinch/lumberjack#type-logger
 func SetRotateLocalTime(val bool) {
+	mu.Lock()
+	defer mu.Unlock()
 	localTime = val
 }
 
 // SetRotateCompress determines if the rotated log files should be compressed using gzip.
 // See: https://github.com/natefinch/lumberjack#type-logger
 func SetRotateCompress(val bool) {
+	mu.Lock()
+	defer mu.Unlock()
 	compress = val
 }
+
+// SetRotateInterval set the interval to rotate the log file by time instead of by size.
+// The period is appended to the file name (e.g. `./log/app-2026-10-17.jsonl`),
+// and the SetRotateFileName value becomes a symlink to the active file.
+// Old files are removed according to SetRotateMaxBackups and SetRotateMaxAge.
+func SetRotateInterval(val RotateInterval) {
+	mu.Lock()
+	defer mu.Unlock()
+	rotateInterval = val
+}
+
+// SetRotateMaxTotalSize set the maximum total size in megabytes of the log file and its backups.
+// The oldest backups (including compressed ones) are deleted until the total size is within the limit.
+// It is checked on each rotation and when Init is called.
+func SetRotateMaxTotalSize(val int) {
+	mu.Lock()
+	defer mu.Unlock()
+	maxTotalSize = val
+}
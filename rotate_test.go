@@ -1,7 +1,14 @@
 package zl
 
 import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -34,3 +41,182 @@ func Test_newRotator(t *testing.T) {
 	})
 
 }
+
+func TestRotate(t *testing.T) {
+	t.Run("not opened", func(t *testing.T) {
+		ResetGlobalLoggerSettings()
+		assert.Error(t, Rotate())
+	})
+
+	t.Run("rotate", func(t *testing.T) {
+		dir := t.TempDir()
+		ResetGlobalLoggerSettings()
+		SetOutput(FileOutput)
+		SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+		Init()
+		defer ResetGlobalLoggerSettings()
+
+		Info("BEFORE_ROTATE")
+		assert.NoError(t, Rotate())
+		Info("AFTER_ROTATE")
+
+		files, err := filepath.Glob(filepath.Join(dir, "app-*.jsonl"))
+		assert.NoError(t, err)
+		assert.Len(t, files, 1)
+		bytes, err := os.ReadFile(files[0])
+		assert.NoError(t, err)
+		assert.Contains(t, string(bytes), "BEFORE_ROTATE")
+
+		bytes, err = os.ReadFile(filepath.Join(dir, "app.jsonl"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(bytes), "AFTER_ROTATE")
+		assert.NotContains(t, string(bytes), "BEFORE_ROTATE")
+	})
+}
+
+func TestRotateWhenHangup(t *testing.T) {
+	dir := t.TempDir()
+	ResetGlobalLoggerSettings()
+	SetOutput(FileOutput)
+	SetLevel(DebugLevel)
+	SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+	Init()
+	defer ResetGlobalLoggerSettings()
+	RotateWhenHangup()
+
+	// simulate logrotate moving the file.
+	Info("BEFORE_MOVE")
+	assert.NoError(t, os.Rename(filepath.Join(dir, "app.jsonl"), filepath.Join(dir, "app.jsonl.1")))
+	assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+
+	assert.Eventually(t, func() bool {
+		bytes, err := os.ReadFile(filepath.Join(dir, "app.jsonl"))
+		return err == nil && strings.Contains(string(bytes), "ROTATE_LOG_FILE")
+	}, time.Second, 10*time.Millisecond)
+
+	bytes, err := os.ReadFile(filepath.Join(dir, "app.jsonl.1"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(bytes), "BEFORE_MOVE")
+	assert.Contains(t, string(bytes), "GOT_SIGNAL_HANGUP")
+}
+
+func Test_listBackups(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"app.jsonl",
+		"app-2026-10-17T10-00-00.000.jsonl.gz",
+		"app-2026-10-17T12-00-00.000.jsonl",
+		"app-invalid.jsonl",
+		"other-2026-10-17T11-00-00.000.jsonl",
+	} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("log\n"), 0o600))
+	}
+	filename := filepath.Join(dir, "app.jsonl")
+	backups, err := listBackups(filename, filename, false, backupTimeFormat)
+	assert.NoError(t, err)
+	assert.Equal(t, []logBackup{
+		{
+			path:      filepath.Join(dir, "app-2026-10-17T12-00-00.000.jsonl"),
+			timestamp: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC),
+			size:      4,
+		},
+		{
+			path:      filepath.Join(dir, "app-2026-10-17T10-00-00.000.jsonl.gz"),
+			timestamp: time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC),
+			size:      4,
+		},
+	}, backups)
+
+	backups, err = listBackups(filepath.Join(dir, "not-found", "app.jsonl"), "", false, backupTimeFormat)
+	assert.NoError(t, err)
+	assert.Empty(t, backups)
+}
+
+func TestSetRotateMaxTotalSize_compress(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.jsonl")
+	older := filepath.Join(dir, "app-2026-10-17T10-00-00.000.jsonl.gz")
+	newer := filepath.Join(dir, "app-2026-10-17T12-00-00.000.jsonl")
+	for name, size := range map[string]int{
+		filename:      100,
+		older:         300,
+		newer:         500,
+		newer + ".gz": 200, // being compressed.
+	} {
+		assert.NoError(t, os.WriteFile(name, bytes.Repeat([]byte("a"), size), 0o600))
+	}
+
+	r := rotateConfig{fileName: filename, maxSize: 1, compress: true}.newSyncer().(*sizeRotator)
+	r.maxTotalSize = 1000
+	assert.NoError(t, r.removeExceedingTotalSize())
+	for _, name := range []string{filename, older, newer, newer + ".gz"} {
+		_, err := os.Stat(name)
+		assert.NoError(t, err, name)
+	}
+
+	r.maxTotalSize = 800
+	assert.NoError(t, r.removeExceedingTotalSize())
+	_, err := os.Stat(older)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSetRotateMaxTotalSize(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.jsonl")
+	backup := func(hour int) string {
+		return filepath.Join(dir, fmt.Sprintf("app-2026-10-17T%02d-00-00.000.jsonl", hour))
+	}
+	half := bytes.Repeat([]byte("a"), megabyte/2)
+	for _, name := range []string{backup(1), backup(2), backup(3), filename} {
+		assert.NoError(t, os.WriteFile(name, half, 0o600))
+	}
+
+	SetOutput(FileOutput)
+	SetRotateFileName(filename)
+	SetRotateMaxBackups(10)
+	SetRotateMaxTotalSize(1)
+	defer ResetGlobalLoggerSettings()
+
+	// checked at Init.
+	Init()
+	for hour, exists := range map[int]bool{1: false, 2: false, 3: true} {
+		_, err := os.Stat(backup(hour))
+		assert.Equal(t, exists, err == nil, hour)
+	}
+
+	// checked on each rotation.
+	_, err := logFile.Write(half)
+	assert.NoError(t, err)
+	assert.NoError(t, Rotate())
+	_, err = os.Stat(backup(3))
+	assert.True(t, os.IsNotExist(err))
+	files, err := filepath.Glob(filepath.Join(dir, "app-*.jsonl"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestSetRotateMaxTotalSize_write(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.jsonl")
+	backup := filepath.Join(dir, "app-2026-10-17T01-00-00.000.jsonl")
+	assert.NoError(t, os.WriteFile(backup, bytes.Repeat([]byte("a"), megabyte+10), 0o600))
+	assert.NoError(t, os.WriteFile(filename, bytes.Repeat([]byte("a"), megabyte-1), 0o600))
+
+	r := rotateConfig{fileName: filename, maxSize: 1, maxBackups: 10, maxTotalSize: 2}.newSyncer()
+	defer r.Close()
+
+	// a write larger than the max size is rejected by lumberjack, and is not a rotation.
+	_, err := r.Write(bytes.Repeat([]byte("a"), megabyte+1))
+	assert.Error(t, err)
+	_, err = os.Stat(backup)
+	assert.NoError(t, err)
+
+	// lumberjack rotates when the file reaches the max size, and the oldest backup is removed.
+	_, err = r.Write([]byte("a"))
+	assert.NoError(t, err)
+	_, err = os.Stat(backup)
+	assert.True(t, os.IsNotExist(err))
+	files, err := filepath.Glob(filepath.Join(dir, "app-*.jsonl"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+}
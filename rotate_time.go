@@ -0,0 +1,296 @@
+package zl
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotateInterval is the interval at which the log file is rotated by time.
+type RotateInterval int
+
+const (
+	// NoInterval rotates the log file only by size. It is Default setting.
+	NoInterval RotateInterval = iota
+	// Hourly rotates the log file every hour. e.g. `app-2026-10-17T15.jsonl`
+	Hourly
+	// Daily rotates the log file every day. e.g. `app-2026-10-17.jsonl`
+	Daily
+	// Weekly rotates the log file every week starting on Monday. e.g. `app-2026-10-12.jsonl`
+	Weekly
+)
+
+var rotateIntervalLayouts = [4]string{
+	"",
+	"2006-01-02T15",
+	"2006-01-02",
+	"2006-01-02",
+}
+
+// backupTimeFormat is the same format as lumberjack's backup file names.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+var currentTime = time.Now
+
+// compressFile is replaced in tests to control the time of compressing.
+var compressFile = gzipFile
+
+// timeRotator is a zapcore.WriteSyncer that writes logs to a file per RotateInterval.
+// The file name is the period appended to the SetRotateFileName value,
+// and that value itself is a symlink to the active file.
+type timeRotator struct {
+	mu           sync.Mutex
+	filename     string
+	interval     RotateInterval
+	maxBackups   int
+	maxAge       int
+	localTime    bool
+	compress     bool
+	maxTotalSize int64 // bytes
+	file         *os.File
+	active       string
+	periodEnd    time.Time
+	millMu       sync.Mutex     // millMu serializes the removal and the compression of the backups.
+	milling      sync.WaitGroup // milling is the running mill goroutines, which Close waits for.
+}
+
+func newTimeRotator() *timeRotator {
+	return getRotateConfig().newTimeRotator()
+}
+
+func (c rotateConfig) newTimeRotator() *timeRotator {
+	return &timeRotator{
+		filename:     c.fileName,
+		interval:     c.interval,
+		maxBackups:   c.maxBackups,
+		maxAge:       c.maxAge,
+		localTime:    c.localTime,
+		compress:     c.compress,
+		maxTotalSize: int64(c.maxTotalSize) * megabyte,
+	}
+}
+
+// Write implements io.Writer.
+func (r *timeRotator) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now := r.now(); r.file == nil || !now.Before(r.periodEnd) {
+		if err := r.open(now); err != nil {
+			return 0, err
+		}
+	}
+	return r.file.Write(p)
+}
+
+// Sync implements zapcore.WriteSyncer.
+func (r *timeRotator) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Sync()
+}
+
+// Rotate reopens the file of the current period.
+func (r *timeRotator) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.open(r.now())
+}
+
+// Close implements io.Closer. It waits for the backups being removed or compressed.
+func (r *timeRotator) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.close()
+	r.milling.Wait()
+	return err
+}
+
+func (r *timeRotator) close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *timeRotator) now() time.Time {
+	if r.localTime {
+		return currentTime()
+	}
+	return currentTime().UTC()
+}
+
+// open closes the current file and opens the file for the period including now.
+func (r *timeRotator) open(now time.Time) error {
+	if err := r.close(); err != nil {
+		return err
+	}
+	start := r.periodStart(now)
+	r.active = r.periodFileName(start)
+	r.periodEnd = r.nextPeriod(start)
+
+	if err := os.MkdirAll(filepath.Dir(r.filename), 0o755); err != nil {
+		return fmt.Errorf("can't make directories for new logfile: %w", err)
+	}
+	f, err := os.OpenFile(r.active, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("can't open new logfile: %w", err)
+	}
+	r.file = f
+
+	if err := r.link(); err != nil {
+		log.Println(err)
+	}
+	r.mill()
+	return nil
+}
+
+// mill removes and compresses the backups in a goroutine like lumberjack,
+// so that the Write opening the file of the next period does not wait for it. r.mu must be held.
+func (r *timeRotator) mill() {
+	active, now := r.active, currentTime()
+	r.milling.Add(1)
+	go func() {
+		defer r.milling.Done()
+		r.millMu.Lock()
+		defer r.millMu.Unlock()
+		if err := r.removeOldFiles(active, now); err != nil {
+			log.Println(err)
+		}
+		if err := r.removeExceedingTotalSizeOf(active); err != nil {
+			log.Println(err)
+		}
+	}()
+}
+
+func (r *timeRotator) periodStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch r.interval {
+	case Hourly:
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, t.Location())
+	case Weekly:
+		return time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+func (r *timeRotator) nextPeriod(start time.Time) time.Time {
+	switch r.interval {
+	case Hourly:
+		return start.Add(time.Hour)
+	case Weekly:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+func (r *timeRotator) prefixAndExt() (prefix, ext string) {
+	ext = filepath.Ext(r.filename)
+	return strings.TrimSuffix(r.filename, ext) + "-", ext
+}
+
+func (r *timeRotator) periodFileName(start time.Time) string {
+	prefix, ext := r.prefixAndExt()
+	return prefix + start.Format(rotateIntervalLayouts[r.interval]) + ext
+}
+
+// link points the SetRotateFileName value to the active file.
+// If a regular file already exists there (e.g. written by size-based rotation),
+// it is kept as a backup instead of being replaced.
+func (r *timeRotator) link() error {
+	if info, err := os.Lstat(r.filename); err == nil && info.Mode()&os.ModeSymlink == 0 {
+		prefix, ext := r.prefixAndExt()
+		backup := prefix + info.ModTime().Format(backupTimeFormat) + ext
+		if err := os.Rename(r.filename, backup); err != nil {
+			return err
+		}
+	}
+	tmp := r.filename + ".tmp"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(filepath.Base(r.active), tmp); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.filename)
+}
+
+// removeOldFiles deletes the backups exceeding MaxBackups or MaxAge, and compresses the rest if set.
+func (r *timeRotator) removeOldFiles(active string, now time.Time) error {
+	backups, err := r.backups(active)
+	if err != nil {
+		return err
+	}
+	cutoff := now.Add(-time.Duration(r.maxAge) * 24 * time.Hour)
+	for i, b := range backups {
+		if (r.maxBackups > 0 && i >= r.maxBackups) || (r.maxAge > 0 && b.timestamp.Before(cutoff)) {
+			err = os.Remove(b.path)
+		} else if r.compress && !strings.HasSuffix(b.path, ".gz") {
+			err = compressFile(b.path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *timeRotator) removeExceedingTotalSize() error {
+	r.mu.Lock()
+	active := r.active
+	r.mu.Unlock()
+	r.millMu.Lock()
+	defer r.millMu.Unlock()
+	return r.removeExceedingTotalSizeOf(active)
+}
+
+func (r *timeRotator) removeExceedingTotalSizeOf(active string) error {
+	if r.maxTotalSize <= 0 {
+		return nil
+	}
+	backups, err := r.backups(active)
+	if err != nil {
+		return err
+	}
+	return removeExceedingTotalSize(active, backups, r.maxTotalSize)
+}
+
+// backups returns the rotated files except the active file, newest first.
+func (r *timeRotator) backups(active string) ([]logBackup, error) {
+	return listBackups(r.filename, active, r.localTime, rotateIntervalLayouts[r.interval], backupTimeFormat)
+}
+
+func gzipFile(src string) (err error) {
+	in, err := os.Open(src) // nolint:gosec
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(src+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(out)
+	if _, err = io.Copy(gz, in); err == nil {
+		err = gz.Close()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
@@ -0,0 +1,152 @@
+package zl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_newFileSyncer(t *testing.T) {
+	_, ok := newFileSyncer().(*timeRotator)
+	assert.False(t, ok)
+
+	SetRotateInterval(Daily)
+	_, ok = newFileSyncer().(*timeRotator)
+	assert.True(t, ok)
+	ResetGlobalLoggerSettings()
+}
+
+func Test_timeRotator_periodFileName(t *testing.T) {
+	now := time.Date(2026, 10, 17, 15, 30, 0, 0, time.UTC) // Saturday
+	tests := []struct {
+		interval RotateInterval
+		expected string
+		next     time.Time
+	}{
+		{Hourly, "log/app-2026-10-17T15.jsonl", time.Date(2026, 10, 17, 16, 0, 0, 0, time.UTC)},
+		{Daily, "log/app-2026-10-17.jsonl", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{Weekly, "log/app-2026-10-12.jsonl", time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			r := &timeRotator{filename: "log/app.jsonl", interval: tt.interval}
+			start := r.periodStart(now)
+			assert.Equal(t, tt.expected, r.periodFileName(start))
+			assert.Equal(t, tt.next, r.nextPeriod(start))
+		})
+	}
+}
+
+func Test_timeRotator_Write(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 17, 23, 59, 0, 0, time.UTC)
+	currentTime = func() time.Time { return now }
+	defer func() { currentTime = time.Now }()
+
+	SetRotateFileName(filepath.Join(dir, "app.jsonl"))
+	SetRotateInterval(Daily)
+	SetRotateMaxBackups(1)
+	defer ResetGlobalLoggerSettings()
+
+	// a file written by size-based rotation is kept as a backup.
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "app.jsonl"), []byte("old\n"), 0o600))
+	old := now.AddDate(0, 0, -1)
+	assert.NoError(t, os.Chtimes(filepath.Join(dir, "app.jsonl"), old, old))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "other.jsonl"), []byte("other\n"), 0o600))
+
+	r := newTimeRotator()
+	_, err := r.Write([]byte("day1\n"))
+	assert.NoError(t, err)
+	now = now.Add(2 * time.Minute)
+	_, err = r.Write([]byte("day2\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, r.Sync())
+	assert.NoError(t, r.Close())
+
+	bytes, err := os.ReadFile(filepath.Join(dir, "app.jsonl"))
+	assert.NoError(t, err)
+	assert.Equal(t, "day2\n", string(bytes))
+
+	link, err := os.Readlink(filepath.Join(dir, "app.jsonl"))
+	assert.NoError(t, err)
+	assert.Equal(t, "app-2026-10-18.jsonl", link)
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"app-2026-10-17.jsonl", "app-2026-10-18.jsonl", "app.jsonl", "other.jsonl"}, names)
+}
+
+func Test_timeRotator_removeOldFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	currentTime = func() time.Time { return now }
+	defer func() { currentTime = time.Now }()
+
+	for _, name := range []string{
+		"app-2026-10-01.jsonl", "app-2026-10-14.jsonl", "app-2026-10-15.jsonl", "app-2026-10-16.jsonl",
+	} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("log\n"), 0o600))
+	}
+
+	r := &timeRotator{
+		filename:   filepath.Join(dir, "app.jsonl"),
+		interval:   Daily,
+		maxBackups: 2,
+		maxAge:     7,
+		compress:   true,
+	}
+	assert.NoError(t, r.removeOldFiles(filepath.Join(dir, "app-2026-10-17.jsonl"), now))
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"app-2026-10-15.jsonl.gz", "app-2026-10-16.jsonl.gz"}, names)
+}
+
+func Test_timeRotator_Write_compressInBackground(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 17, 23, 59, 0, 0, time.UTC)
+	currentTime = func() time.Time { return now }
+	defer func() { currentTime = time.Now }()
+	release := make(chan struct{})
+	compressFile = func(src string) error {
+		<-release
+		return gzipFile(src)
+	}
+	defer func() { compressFile = gzipFile }()
+
+	r := &timeRotator{filename: filepath.Join(dir, "app.jsonl"), interval: Daily, compress: true}
+	_, err := r.Write([]byte("day1\n"))
+	assert.NoError(t, err)
+	now = now.Add(2 * time.Minute)
+
+	// the Write across the boundary returns while the backup is being compressed.
+	written := make(chan error)
+	go func() {
+		_, err := r.Write([]byte("day2\n"))
+		written <- err
+	}()
+	select {
+	case err := <-written:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Write waited for the compression")
+	}
+	close(release)
+	assert.NoError(t, r.Close())
+
+	_, err = os.Stat(filepath.Join(dir, "app-2026-10-17.jsonl.gz"))
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "app-2026-10-17.jsonl"))
+	assert.True(t, os.IsNotExist(err))
+}
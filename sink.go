@@ -0,0 +1,183 @@
+package zl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Encoder is the format in which a sink writes the log entries.
+type Encoder int
+
+const (
+	// JSONEncoder writes the entries as JSON lines. It is Default setting.
+	JSONEncoder Encoder = iota
+	// ConsoleEncoder writes the entries in zap's human-readable console format.
+	ConsoleEncoder
+	// LogfmtEncoder writes the entries as logfmt key=value pairs.
+	LogfmtEncoder
+)
+
+// SinkOptions is the settings of a sink added by AddSink.
+type SinkOptions struct {
+	// Level is the minimum level written to the sink. Default is InfoLevel.
+	Level zapcore.Level
+	// Encoder is the format of the entries written to the sink. Default is JSONEncoder.
+	// The writers of this package that convert the entries (e.g. SyslogWriter or OTLPWriter) always use JSONEncoder.
+	Encoder Encoder
+}
+
+// jsonSink is implemented by the writers that parse the entries encoded by JSONEncoder.
+type jsonSink interface {
+	requiresJSON()
+}
+
+// sink is an additional output destination.
+type sink struct {
+	name    string
+	syncer  zapcore.WriteSyncer
+	options SinkOptions
+}
+
+var sinks []*sink
+
+// AddSink adds an output destination such as a pipe, a socket or an in-memory buffer,
+// in addition to the output destinations set by SetOutput.
+// Each sink has its own minimum level and encoder.
+// A sink with the same name is replaced. It must be called before Init.
+//
+// e.g. `zl.AddSink("buffer", zapcore.AddSync(&buf), zl.SinkOptions{Level: zl.WarnLevel, Encoder: zl.LogfmtEncoder})`
+func AddSink(name string, ws zapcore.WriteSyncer, options SinkOptions) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := ws.(jsonSink); ok && options.Encoder != JSONEncoder {
+		log.Printf("the sink %q uses JSONEncoder, as the writer parses the entries encoded by JSONEncoder", name)
+		options.Encoder = JSONEncoder
+	}
+	s := &sink{name: name, syncer: ws, options: options}
+	for i := range sinks {
+		if sinks[i].name == name {
+			sinks[i] = s
+			return
+		}
+	}
+	sinks = append(sinks, s)
+}
+
+// removeClosers removes the sinks that implement io.Closer, except stdout and stderr, and returns them.
+// They are removed as they cannot be written after closed. mu must be held.
+func removeClosers() (ret []io.Closer) {
+	var kept []*sink
+	for _, s := range sinks {
+		if f, ok := s.syncer.(*os.File); ok && (f == os.Stdout || f == os.Stderr) {
+			kept = append(kept, s)
+			continue
+		}
+		if c, ok := s.syncer.(io.Closer); ok {
+			ret = append(ret, c)
+			continue
+		}
+		kept = append(kept, s)
+	}
+	sinks = kept
+	return ret
+}
+
+func (s *sink) newCore(enc *zapcore.EncoderConfig) zapcore.Core {
+	var encoder zapcore.Encoder
+	switch s.options.Encoder {
+	case ConsoleEncoder:
+		encoder = zapcore.NewConsoleEncoder(*enc)
+	case LogfmtEncoder:
+		encoder = newLogfmtEncoder(*enc)
+	default:
+		encoder = zapcore.NewJSONEncoder(*enc)
+	}
+	return zapcore.NewCore(encoder, s.syncer, s.options.Level)
+}
+
+// appName returns the name set by SetServiceName, or the name of the executable.
+// It is the default name of the application in the sinks.
+func appName() string {
+	mu.Lock()
+	defer mu.Unlock()
+	if serviceName != "" {
+		return serviceName
+	}
+	return filepath.Base(os.Args[0])
+}
+
+// parseEntry parses a log entry encoded by JSONEncoder.
+// It is used by the sinks that convert the entries to other formats.
+func parseEntry(p []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	var m map[string]interface{}
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// dropNonJSON wraps the send function of a batch sink to drop the entries not encoded by JSONEncoder
+// with an error, instead of the sink skipping them silently.
+func dropNonJSON(send func(entries [][]byte) error) func(entries [][]byte) error {
+	return func(entries [][]byte) error {
+		valid := make([][]byte, 0, len(entries))
+		for _, p := range entries {
+			if _, err := parseEntry(p); err == nil {
+				valid = append(valid, p)
+			}
+		}
+		if len(valid) == len(entries) {
+			return send(entries)
+		}
+		dropped := fmt.Errorf("%d entries are dropped as they are not encoded by JSONEncoder", len(entries)-len(valid))
+		if len(valid) == 0 {
+			return &partialError{dropped: dropped, err: dropped}
+		}
+		err := send(valid)
+		var partial *partialError
+		switch {
+		case err == nil:
+			return &partialError{dropped: dropped, err: dropped}
+		case errors.As(err, &partial):
+			return &partialError{entries: partial.entries, dropped: errors.Join(dropped, partial.dropped), err: err}
+		default:
+			return &partialError{entries: valid, dropped: dropped, err: err}
+		}
+	}
+}
+
+// entryString returns the string field of the entry with the key.
+func entryString(m map[string]interface{}, key Key) string {
+	if s, ok := m[fieldKey(key)].(string); ok {
+		return s
+	}
+	return ""
+}
+
+// entryLevel returns the level of the entry. It is InfoLevel if the LevelKey is omitted.
+func entryLevel(m map[string]interface{}) zapcore.Level {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(entryString(m, LevelKey))); err != nil {
+		return InfoLevel
+	}
+	return level
+}
+
+// entryTime returns the time of the entry. It is the current time if the TimeKey is omitted.
+func entryTime(m map[string]interface{}) time.Time {
+	if t, err := time.Parse(time.RFC3339Nano, entryString(m, TimeKey)); err == nil {
+		return t
+	}
+	return currentTime()
+}
@@ -0,0 +1,67 @@
+package zl
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestAddSink(t *testing.T) {
+	var jsonBuf, consoleBuf, logfmtBuf, replacedBuf bytes.Buffer
+	SetOutput(ConsoleOutput)
+	SetLevel(DebugLevel)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, StacktraceKey, PIDKey)
+	AddSink("json", zapcore.AddSync(&replacedBuf), SinkOptions{})
+	AddSink("json", zapcore.AddSync(&jsonBuf), SinkOptions{Level: WarnLevel})
+	AddSink("console", zapcore.AddSync(&consoleBuf), SinkOptions{Level: DebugLevel, Encoder: ConsoleEncoder})
+	AddSink("logfmt", zapcore.AddSync(&logfmtBuf), SinkOptions{Encoder: LogfmtEncoder})
+	defer ResetGlobalLoggerSettings()
+	assert.Len(t, sinks, 3)
+
+	Init()
+	Debug("DEBUG_MESSAGE")
+	Info("INFO_MESSAGE", zap.String("user", "Alice Smith"))
+	Warn("WARN_MESSAGE", zap.Int("count", 1))
+
+	assert.Empty(t, replacedBuf.String())
+	assert.Equal(t, `{"severity":"WARN","message":"WARN_MESSAGE","count":1}`+"\n", jsonBuf.String())
+	assert.Equal(t, ""+
+		"DEBUG\tINIT_LOGGER\t{\"console\": \"Severity: DEBUG, Output: Console\"}\n"+
+		"DEBUG\tDEBUG_MESSAGE\n"+
+		"INFO\tINFO_MESSAGE\t{\"user\": \"Alice Smith\"}\n"+
+		"WARN\tWARN_MESSAGE\t{\"count\": 1}\n",
+		consoleBuf.String(),
+	)
+	assert.Equal(t, ""+
+		"severity=INFO message=INFO_MESSAGE user=\"Alice Smith\"\n"+
+		"severity=WARN message=WARN_MESSAGE count=1\n",
+		logfmtBuf.String(),
+	)
+}
+
+func TestAddSink_jsonSink(t *testing.T) {
+	w := NewLokiWriter(LokiOptions{URL: "http://127.0.0.1:0/loki/api/v1/push"})
+	defer w.Close()
+	AddSink("loki", w, SinkOptions{Encoder: LogfmtEncoder})
+	defer ResetGlobalLoggerSettings()
+
+	assert.Equal(t, JSONEncoder, sinks[0].options.Encoder)
+}
+
+func Test_dropNonJSON(t *testing.T) {
+	s := &testSender{}
+	b := newBatchWriter(BatchOptions{Interval: time.Hour}, dropNonJSON(s.send))
+	defer b.Close()
+
+	for _, e := range []string{`{"message":"FIRST"}`, `message=SECOND`, `{"message":"THIRD"}`} {
+		_, err := b.Write([]byte(e))
+		assert.NoError(t, err)
+	}
+	assert.ErrorContains(t, b.Sync(), "1 entries are dropped as they are not encoded by JSONEncoder")
+	assert.Equal(t, [][]string{{`{"message":"FIRST"}`, `{"message":"THIRD"}`}}, s.get())
+	assert.NoError(t, b.Sync())
+}
@@ -0,0 +1,123 @@
+package zl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// SplunkOptions is the settings of the Splunk sink.
+type SplunkOptions struct {
+	// URL is the URL of the event endpoint. Default is "https://localhost:8088/services/collector/event".
+	URL string
+	// Token is the HEC token.
+	Token string
+	// Source is the source of the events. Default is the name set by SetServiceName or the name of the executable.
+	Source string
+	// SourceType is the sourcetype of the events. Default is "_json".
+	SourceType string
+	// Index is the index of the events. Default is the default index of the token.
+	Index string
+	// Timeout is the timeout of a request. Default is 10 seconds.
+	Timeout time.Duration
+	// Batch is the settings of batching.
+	Batch BatchOptions
+}
+
+// SplunkWriter is a zapcore.WriteSyncer that sends the entries encoded by JSONEncoder
+// to the Splunk HTTP Event Collector.
+// The entry is the event, the hostname field is the host, and the version field is an indexed field.
+// See: https://docs.splunk.com/Documentation/Splunk/latest/Data/FormateventsforHTTPEventCollector
+//
+// A typical usage would be something like.
+//
+//	w := zl.NewSplunkWriter(zl.SplunkOptions{URL: "https://splunk:8088/services/collector/event", Token: token})
+//	zl.AddSink("splunk", w, zl.SinkOptions{Level: zl.InfoLevel})
+//	zl.Init()
+//	defer w.Close()
+type SplunkWriter struct {
+	*batchWriter
+	options  SplunkOptions
+	client   *http.Client
+	hostname string
+}
+
+// NewSplunkWriter returns the SplunkWriter.
+func NewSplunkWriter(options SplunkOptions) *SplunkWriter {
+	if options.URL == "" {
+		options.URL = "https://localhost:8088/services/collector/event"
+	}
+	if options.Source == "" {
+		options.Source = appName()
+	}
+	if options.SourceType == "" {
+		options.SourceType = "_json"
+	}
+	if options.Timeout == 0 {
+		options.Timeout = 10 * time.Second
+	}
+	w := &SplunkWriter{options: options, client: &http.Client{Timeout: options.Timeout}}
+	if h := getHost(); h != nil {
+		w.hostname = *h
+	}
+	w.batchWriter = newBatchWriter(options.Batch, dropNonJSON(w.send))
+	return w
+}
+
+// requiresJSON implements jsonSink.
+func (w *SplunkWriter) requiresJSON() {}
+
+type splunkEvent struct {
+	Time       float64                `json:"time"`
+	Host       string                 `json:"host"`
+	Source     string                 `json:"source"`
+	SourceType string                 `json:"sourcetype"`
+	Index      string                 `json:"index,omitempty"`
+	Fields     map[string]interface{} `json:"fields,omitempty"`
+	Event      json.RawMessage        `json:"event"`
+}
+
+func (w *SplunkWriter) send(entries [][]byte) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, p := range entries {
+		m, err := parseEntry(p)
+		if err != nil {
+			continue
+		}
+		if err := enc.Encode(w.event(m, p)); err != nil {
+			continue
+		}
+	}
+	if body.Len() == 0 {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, w.options.URL, &body)
+	if err != nil {
+		return &permanentError{err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Splunk "+w.options.Token)
+	return postRequest(w.client, req)
+}
+
+func (w *SplunkWriter) event(m map[string]interface{}, entry []byte) *splunkEvent {
+	e := &splunkEvent{
+		Time:       float64(entryTime(m).UnixMilli()) / 1000,
+		Host:       entryString(m, HostnameKey),
+		Source:     w.options.Source,
+		SourceType: w.options.SourceType,
+		Index:      w.options.Index,
+		Event:      bytes.TrimRight(entry, "\n"),
+	}
+	if e.Host == "" {
+		e.Host = w.hostname
+	}
+	if v := entryString(m, VersionKey); v != "" {
+		e.Fields = map[string]interface{}{fieldKey(VersionKey): v}
+	}
+	return e
+}
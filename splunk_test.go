@@ -0,0 +1,81 @@
+package zl
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplunkWriter(t *testing.T) {
+	var calls atomic.Int32
+	bodies := make(chan []byte, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/services/collector/event", r.URL.Path)
+		assert.Equal(t, "Splunk token1", r.Header.Get("Authorization"))
+		b, _ := io.ReadAll(r.Body)
+		bodies <- b
+		// the first request fails, and the entries are sent again at the next Sync.
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"text":"Server is busy","code":9}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"text":"Success","code":0}`))
+	}))
+	defer server.Close()
+
+	w := NewSplunkWriter(SplunkOptions{
+		URL:    server.URL + "/services/collector/event",
+		Token:  "token1",
+		Source: "my-app",
+		Index:  "main",
+		Batch:  BatchOptions{Interval: time.Hour, RetryBackoff: time.Millisecond},
+	})
+	defer w.Close()
+
+	for _, entry := range []string{
+		`{"severity":"INFO","timestamp":"2026-10-17T12:00:00.123Z","message":"FIRST","version":"v1.0.0","hostname":"host1"}` + "\n",
+		`{"severity":"WARN","timestamp":"2026-10-17T12:00:01Z","message":"SECOND"}` + "\n",
+	} {
+		_, err := w.Write([]byte(entry))
+		assert.NoError(t, err)
+	}
+	assert.Error(t, w.Sync())
+	assert.NoError(t, w.Sync())
+	assert.Equal(t, int32(2), calls.Load())
+	body := <-bodies
+	assert.Equal(t, body, <-bodies)
+
+	var events []map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	for dec.More() {
+		var e map[string]interface{}
+		assert.NoError(t, dec.Decode(&e))
+		events = append(events, e)
+	}
+	assert.Len(t, events, 2)
+	assert.Equal(t, map[string]interface{}{
+		"time":       1792238400.123,
+		"host":       "host1",
+		"source":     "my-app",
+		"sourcetype": "_json",
+		"index":      "main",
+		"fields":     map[string]interface{}{"version": "v1.0.0"},
+		"event": map[string]interface{}{
+			"severity":  "INFO",
+			"timestamp": "2026-10-17T12:00:00.123Z",
+			"message":   "FIRST",
+			"version":   "v1.0.0",
+			"hostname":  "host1",
+		},
+	}, events[0])
+	assert.Equal(t, w.hostname, events[1]["host"])
+	assert.NotContains(t, events[1], "fields")
+}
@@ -0,0 +1,227 @@
+package zl
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// state is the loggers built from the settings by Init or Reconfigure, and the settings read while logging.
+// It is published atomically and never modified, so logging does not race with the setters.
+type state struct {
+	core           zapcore.Core // core writes to all the outputs, and has the additional fields.
+	zapLogger      *zap.Logger
+	internalLogger *zap.Logger
+	options        []zap.Option
+	pretty         *prettyLogger
+
+	outputType           Output
+	level                zapcore.Level
+	fileName             string
+	reportFileName       string
+	pid                  int
+	traceProfile         TraceProfile
+	googleCloudProjectID string
+	recordSpanEvents     bool
+
+	files   []rotateSyncer // files are the log files opened for the state, which are closed when it is replaced.
+	buffers []*zapcore.BufferedWriteSyncer
+
+	// writers is the number of the writes in progress. close sets closed and waits for them,
+	// so that the files are not written or reopened after they are closed.
+	// A mutex is not held while writing, as the hooks and the sinks may log.
+	writeMu sync.Mutex
+	writers int
+	closed  bool
+	idle    *sync.Cond // idle is signaled when writers becomes zero after closed is set.
+}
+
+// emptyState is used before Init, and writes nothing.
+var emptyState = &state{
+	core:           zapcore.NewNopCore(),
+	zapLogger:      zap.NewNop(),
+	internalLogger: zap.NewNop(),
+}
+
+// loadState returns the state published by Init or Reconfigure, or emptyState before Init.
+func loadState() *state {
+	if s := loaded.Load(); s != nil {
+		return s
+	}
+	return emptyState
+}
+
+// newState builds the loggers from the settings. mu must be held.
+func newState() *state {
+	buffers = nil
+	opts := loggerOptions()
+	fields := getAdditionalFields()
+	core := zapcore.NewTee(getCores(newEncoderConfig())...).With(fields)
+
+	encInternal := newEncoderConfig()
+	encInternal.EncodeCaller = zapcore.ShortCallerEncoder
+	internalCore := zapcore.NewTee(getCores(encInternal)...).With(fields)
+
+	var pid int
+	if !lo.Contains(omitKeys, PIDKey) {
+		pid = os.Getpid()
+	}
+	s := &state{
+		options:              opts,
+		pretty:               newPrettyLogger(getConsoleOutput(), os.Stderr),
+		outputType:           outputType,
+		level:                severityLevel,
+		fileName:             fileName,
+		reportFileName:       reportFileName(),
+		pid:                  pid,
+		traceProfile:         traceProfile,
+		googleCloudProjectID: googleCloudProjectID,
+		recordSpanEvents:     recordSpanEvents,
+		files:                openedFiles(),
+		buffers:              buffers,
+	}
+	s.core = newStateCore(s, core, func(s *state) zapcore.Core { return s.core })
+	s.zapLogger = zap.New(s.core, opts...)
+	s.internalLogger = zap.New(newStateCore(s, internalCore, func(s *state) zapcore.Core { return s.internalLogger.Core() }), opts...)
+	return s
+}
+
+// String returns the settings displayed when the logger is initialized.
+func (s *state) String() string {
+	var p, f string
+	if s.pid != 0 {
+		p = fmt.Sprintf(", PID: %d", s.pid)
+	}
+	if s.outputType == PrettyOutput || s.outputType == ConsoleAndFileOutput {
+		f = fmt.Sprintf(", File: %s", s.fileName)
+	}
+	return fmt.Sprintf(
+		"Severity: %s, Output: %s%s%s",
+		s.level.CapitalString(),
+		s.outputType.String(),
+		f,
+		p,
+	)
+}
+
+func (s *state) removeExceedingTotalSize() {
+	for _, f := range s.files {
+		if err := f.removeExceedingTotalSize(); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// close flushes the buffers and closes the log files of the state replaced by Reconfigure or Close.
+// It waits for the entries being written, and the entries written after it are passed to the current state.
+func (s *state) close() error {
+	s.writeMu.Lock()
+	s.closed = true
+	for s.writers > 0 {
+		if s.idle == nil {
+			s.idle = sync.NewCond(&s.writeMu)
+		}
+		s.idle.Wait()
+	}
+	s.writeMu.Unlock()
+
+	s.stopBuffers()
+	var errs []error
+	for _, f := range s.files {
+		errs = append(errs, f.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// acquire counts a write in progress, and reports false when the state is closed.
+func (s *state) acquire() bool {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.writers++
+	return true
+}
+
+// release ends the write counted by acquire.
+func (s *state) release() {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.writers--
+	if s.writers == 0 && s.idle != nil {
+		s.idle.Broadcast()
+	}
+}
+
+// use runs f unless the state is closed, so that f does not write to the closed files.
+func (s *state) use(f func()) {
+	if s.acquire() {
+		defer s.release()
+		f()
+	}
+}
+
+// stateCore is the core of a state. The entries written after the state is closed
+// are written by the same core of the current state, so that they are not lost.
+type stateCore struct {
+	zapcore.Core
+	state  *state
+	root   func(*state) zapcore.Core // root returns the core of the state to write to after it is closed.
+	fields []zapcore.Field           // fields are the fields added by With.
+}
+
+func newStateCore(s *state, core zapcore.Core, root func(*state) zapcore.Core) *stateCore {
+	return &stateCore{Core: core, state: s, root: root}
+}
+
+// With implements zapcore.Core.
+func (c *stateCore) With(fields []zapcore.Field) zapcore.Core {
+	return &stateCore{
+		Core:   c.Core.With(fields),
+		state:  c.state,
+		root:   c.root,
+		fields: append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+// Check implements zapcore.Core. The cores to write to are checked again in Write.
+func (c *stateCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write implements zapcore.Core.
+func (c *stateCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	core := c.Core
+	if c.state.acquire() {
+		defer c.state.release()
+	} else {
+		core = c.root(loadState())
+		if len(c.fields) > 0 {
+			core = core.With(c.fields)
+		}
+	}
+	if ce := core.Check(ent, nil); ce != nil {
+		ce.ErrorOutput = zapcore.Lock(os.Stderr)
+		ce.Write(fields...)
+	}
+	return nil
+}
+
+// Sync implements zapcore.Core.
+func (c *stateCore) Sync() error {
+	var err error
+	c.state.use(func() {
+		err = c.Core.Sync()
+	})
+	return err
+}
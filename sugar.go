@@ -0,0 +1,313 @@
+package zl
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SugaredLogger is a wrapper of Zap's SugaredLogger.
+// It provides the printf-style (e.g. Infof) and the key-value style (e.g. Infow) methods
+// that also work with PrettyOutput, the console fields and the error report.
+// It is slower than Logger, so use it only where the convenience matters. e.g. migrating from logrus.
+//
+// A typical usage would be something like.
+//
+//	sugar := zl.New(zl.Console("api")).Sugar()
+//	sugar.Infof("fetched %d users", len(users))
+//	sugar.Infow("USER_FETCHED", "user_id", 42, zl.Console("user 42"))
+type SugaredLogger struct {
+	logger *Logger
+	sugar  *zap.SugaredLogger
+}
+
+// Sugar returns the SugaredLogger that has the same fields and name as the Logger.
+func (l *Logger) Sugar() *SugaredLogger {
+	return &SugaredLogger{logger: l, sugar: l.zapLogger.Sugar()}
+}
+
+// Desugar returns the Logger of the SugaredLogger.
+func (s *SugaredLogger) Desugar() *Logger {
+	return s.logger
+}
+
+// Debugf formats the message with fmt.Sprintf and outputs a DEBUG log.
+func (s *SugaredLogger) Debugf(template string, args ...interface{}) {
+	if s.enabled(DebugLevel) {
+		s.log(sugarMessage(template, args), DebugLevel, nil).Debugf(template, args...)
+	}
+}
+
+// Infof formats the message with fmt.Sprintf and outputs an INFO log.
+func (s *SugaredLogger) Infof(template string, args ...interface{}) {
+	if s.enabled(InfoLevel) {
+		s.log(sugarMessage(template, args), InfoLevel, nil).Infof(template, args...)
+	}
+}
+
+// Warnf formats the message with fmt.Sprintf and outputs a WARN log.
+func (s *SugaredLogger) Warnf(template string, args ...interface{}) {
+	if s.enabled(WarnLevel) {
+		s.log(sugarMessage(template, args), WarnLevel, nil).Warnf(template, args...)
+	}
+}
+
+// Errorf formats the message with fmt.Sprintf and outputs an ERROR log.
+func (s *SugaredLogger) Errorf(template string, args ...interface{}) {
+	if s.enabled(ErrorLevel) {
+		s.log(sugarMessage(template, args), ErrorLevel, nil).Errorf(template, args...)
+	}
+}
+
+// DPanicf formats the message with fmt.Sprintf and outputs a DPANIC log.
+// It panics after writing the log only in the development mode. See: SetDevelopment
+func (s *SugaredLogger) DPanicf(template string, args ...interface{}) {
+	if s.enabled(DPanicLevel) {
+		s.log(sugarMessage(template, args), DPanicLevel, nil).DPanicf(template, args...)
+	}
+}
+
+// Panicf formats the message with fmt.Sprintf and outputs a PANIC log.
+func (s *SugaredLogger) Panicf(template string, args ...interface{}) {
+	if s.enabled(PanicLevel) {
+		s.log(sugarMessage(template, args), PanicLevel, nil).Panicf(template, args...)
+	}
+}
+
+// Fatalf formats the message with fmt.Sprintf and outputs a FATAL log.
+func (s *SugaredLogger) Fatalf(template string, args ...interface{}) {
+	if s.enabled(FatalLevel) {
+		s.log(sugarMessage(template, args), FatalLevel, nil).Fatalf(template, args...)
+	}
+}
+
+// Debugw outputs a DEBUG log with the loosely-typed key-value pairs.
+// zap.Field values such as zl.Console can also be mixed in.
+func (s *SugaredLogger) Debugw(message string, keysAndValues ...interface{}) {
+	if s.enabled(DebugLevel) {
+		s.log(message, DebugLevel, sweetenFields(keysAndValues)).Debugw(message, keysAndValues...)
+	}
+}
+
+// Infow outputs an INFO log with the loosely-typed key-value pairs.
+// zap.Field values such as zl.Console can also be mixed in.
+func (s *SugaredLogger) Infow(message string, keysAndValues ...interface{}) {
+	if s.enabled(InfoLevel) {
+		s.log(message, InfoLevel, sweetenFields(keysAndValues)).Infow(message, keysAndValues...)
+	}
+}
+
+// Warnw outputs a WARN log with the loosely-typed key-value pairs.
+// zap.Field values such as zl.Console can also be mixed in.
+func (s *SugaredLogger) Warnw(message string, keysAndValues ...interface{}) {
+	if s.enabled(WarnLevel) {
+		s.log(message, WarnLevel, sweetenFields(keysAndValues)).Warnw(message, keysAndValues...)
+	}
+}
+
+// Errorw outputs an ERROR log with the loosely-typed key-value pairs.
+// zap.Field values such as zl.Console can also be mixed in.
+func (s *SugaredLogger) Errorw(message string, keysAndValues ...interface{}) {
+	if s.enabled(ErrorLevel) {
+		s.log(message, ErrorLevel, sweetenFields(keysAndValues)).Errorw(message, keysAndValues...)
+	}
+}
+
+// DPanicw outputs a DPANIC log with the loosely-typed key-value pairs.
+// It panics after writing the log only in the development mode. See: SetDevelopment
+func (s *SugaredLogger) DPanicw(message string, keysAndValues ...interface{}) {
+	if s.enabled(DPanicLevel) {
+		s.log(message, DPanicLevel, sweetenFields(keysAndValues)).DPanicw(message, keysAndValues...)
+	}
+}
+
+// Panicw outputs a PANIC log with the loosely-typed key-value pairs.
+func (s *SugaredLogger) Panicw(message string, keysAndValues ...interface{}) {
+	if s.enabled(PanicLevel) {
+		s.log(message, PanicLevel, sweetenFields(keysAndValues)).Panicw(message, keysAndValues...)
+	}
+}
+
+// Fatalw outputs a FATAL log with the loosely-typed key-value pairs.
+func (s *SugaredLogger) Fatalw(message string, keysAndValues ...interface{}) {
+	if s.enabled(FatalLevel) {
+		s.log(message, FatalLevel, sweetenFields(keysAndValues)).Fatalw(message, keysAndValues...)
+	}
+}
+
+// enabled reports whether the level is written, so that the message is not formatted for the disabled logs.
+func (s *SugaredLogger) enabled(level zapcore.Level) bool {
+	return levelEnabled(s.logger.zapLogger.Core(), level)
+}
+
+func (s *SugaredLogger) log(message string, level zapcore.Level, fields []zap.Field) *zap.SugaredLogger {
+	fields = append(fields, s.logger.fields...)
+	s.logger.prettyLogger().log(message, level, fields)
+	s.logger.addSpanEvent(message, level, nil, fields)
+	return s.sugar
+}
+
+// Debugf formats the message with fmt.Sprintf and outputs a DEBUG log.
+func Debugf(template string, args ...interface{}) {
+	if sugarEnabled(DebugLevel) {
+		sugarLogger(sugarMessage(template, args), DebugLevel, nil).Debugf(template, args...)
+	}
+}
+
+// Infof formats the message with fmt.Sprintf and outputs an INFO log.
+func Infof(template string, args ...interface{}) {
+	if sugarEnabled(InfoLevel) {
+		sugarLogger(sugarMessage(template, args), InfoLevel, nil).Infof(template, args...)
+	}
+}
+
+// Warnf formats the message with fmt.Sprintf and outputs a WARN log.
+func Warnf(template string, args ...interface{}) {
+	if sugarEnabled(WarnLevel) {
+		sugarLogger(sugarMessage(template, args), WarnLevel, nil).Warnf(template, args...)
+	}
+}
+
+// Errorf formats the message with fmt.Sprintf and outputs an ERROR log.
+func Errorf(template string, args ...interface{}) {
+	if sugarEnabled(ErrorLevel) {
+		sugarLogger(sugarMessage(template, args), ErrorLevel, nil).Errorf(template, args...)
+	}
+}
+
+// DPanicf formats the message with fmt.Sprintf and outputs a DPANIC log.
+// It panics after writing the log only in the development mode. See: SetDevelopment
+func DPanicf(template string, args ...interface{}) {
+	if sugarEnabled(DPanicLevel) {
+		sugarLogger(sugarMessage(template, args), DPanicLevel, nil).DPanicf(template, args...)
+	}
+}
+
+// Panicf formats the message with fmt.Sprintf and outputs a PANIC log.
+func Panicf(template string, args ...interface{}) {
+	if sugarEnabled(PanicLevel) {
+		sugarLogger(sugarMessage(template, args), PanicLevel, nil).Panicf(template, args...)
+	}
+}
+
+// Fatalf formats the message with fmt.Sprintf and outputs a FATAL log.
+func Fatalf(template string, args ...interface{}) {
+	if sugarEnabled(FatalLevel) {
+		sugarLogger(sugarMessage(template, args), FatalLevel, nil).Fatalf(template, args...)
+	}
+}
+
+// Debugw outputs a DEBUG log with the loosely-typed key-value pairs.
+// zap.Field values such as zl.Console can also be mixed in.
+func Debugw(message string, keysAndValues ...interface{}) {
+	if sugarEnabled(DebugLevel) {
+		sugarLogger(message, DebugLevel, sweetenFields(keysAndValues)).Debugw(message, keysAndValues...)
+	}
+}
+
+// Infow outputs an INFO log with the loosely-typed key-value pairs.
+// zap.Field values such as zl.Console can also be mixed in.
+//
+// A typical usage would be something like.
+//
+//	zl.Infow("USER_FETCHED", "user_id", 42, "name", name)
+func Infow(message string, keysAndValues ...interface{}) {
+	if sugarEnabled(InfoLevel) {
+		sugarLogger(message, InfoLevel, sweetenFields(keysAndValues)).Infow(message, keysAndValues...)
+	}
+}
+
+// Warnw outputs a WARN log with the loosely-typed key-value pairs.
+// zap.Field values such as zl.Console can also be mixed in.
+func Warnw(message string, keysAndValues ...interface{}) {
+	if sugarEnabled(WarnLevel) {
+		sugarLogger(message, WarnLevel, sweetenFields(keysAndValues)).Warnw(message, keysAndValues...)
+	}
+}
+
+// Errorw outputs an ERROR log with the loosely-typed key-value pairs.
+// zap.Field values such as zl.Console can also be mixed in.
+func Errorw(message string, keysAndValues ...interface{}) {
+	if sugarEnabled(ErrorLevel) {
+		sugarLogger(message, ErrorLevel, sweetenFields(keysAndValues)).Errorw(message, keysAndValues...)
+	}
+}
+
+// DPanicw outputs a DPANIC log with the loosely-typed key-value pairs.
+// It panics after writing the log only in the development mode. See: SetDevelopment
+func DPanicw(message string, keysAndValues ...interface{}) {
+	if sugarEnabled(DPanicLevel) {
+		sugarLogger(message, DPanicLevel, sweetenFields(keysAndValues)).DPanicw(message, keysAndValues...)
+	}
+}
+
+// Panicw outputs a PANIC log with the loosely-typed key-value pairs.
+func Panicw(message string, keysAndValues ...interface{}) {
+	if sugarEnabled(PanicLevel) {
+		sugarLogger(message, PanicLevel, sweetenFields(keysAndValues)).Panicw(message, keysAndValues...)
+	}
+}
+
+// Fatalw outputs a FATAL log with the loosely-typed key-value pairs.
+func Fatalw(message string, keysAndValues ...interface{}) {
+	if sugarEnabled(FatalLevel) {
+		sugarLogger(message, FatalLevel, sweetenFields(keysAndValues)).Fatalw(message, keysAndValues...)
+	}
+}
+
+func sugarEnabled(level zapcore.Level) bool {
+	return levelEnabled(logger().Core(), level)
+}
+
+func sugarLogger(message string, level zapcore.Level, fields []zap.Field) *zap.SugaredLogger {
+	s := loadState()
+	s.pretty.log(message, level, fields)
+	return s.zapLogger.Sugar()
+}
+
+// levelEnabled reports whether the level is written by the core in the same way as Zap's Check.
+// DPANIC or higher level logs are always checked because they panic or exit even if they are not written.
+func levelEnabled(core zapcore.Core, level zapcore.Level) bool {
+	return level >= DPanicLevel || core.Enabled(level)
+}
+
+// sugarMessage formats the message in the same way as Zap's SugaredLogger.
+func sugarMessage(template string, args []interface{}) string {
+	if len(args) == 0 {
+		return template
+	}
+	if template != "" {
+		return fmt.Sprintf(template, args...)
+	}
+	if len(args) == 1 {
+		if s, ok := args[0].(string); ok {
+			return s
+		}
+	}
+	return fmt.Sprint(args...)
+}
+
+// sweetenFields converts the key-value pairs to the fields in the same way as Zap's SugaredLogger,
+// so that the console fields are displayed in PrettyOutput. Invalid pairs are reported by Zap.
+func sweetenFields(keysAndValues []interface{}) []zap.Field {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+	fields := make([]zap.Field, 0, len(keysAndValues))
+	for i := 0; i < len(keysAndValues); {
+		if f, ok := keysAndValues[i].(zap.Field); ok {
+			fields = append(fields, f)
+			i++
+			continue
+		}
+		if i == len(keysAndValues)-1 {
+			break
+		}
+		if key, ok := keysAndValues[i].(string); ok {
+			fields = append(fields, zap.Any(key, keysAndValues[i+1]))
+		}
+		i += 2
+	}
+	return fields
+}
@@ -0,0 +1,60 @@
+package zl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestSugaredLogger(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "app.jsonl")
+	SetOutput(FileOutput)
+	SetLevel(DebugLevel)
+	SetRotateFileName(fileName)
+	SetOmitKeys(TimeKey, FunctionKey, VersionKey, HostnameKey, PIDKey, StacktraceKey)
+	defer ResetGlobalLoggerSettings()
+	Init()
+
+	Infof("fetched %d users", 3)
+	Debugw("USER_FETCHED", "user_id", 42, Console("user 42"))
+	sugar := New(zap.String("request_id", "1")).Named("api").Sugar()
+	sugar.Warnf("retry %s", "later")
+	sugar.Errorw("USER_NOT_FOUND", "user_id", 42)
+	Sync()
+
+	b, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	assert.Equal(t, []string{
+		`{"severity":"INFO","caller":"zl/sugar_test.go:23","message":"fetched 3 users"}`,
+		`{"severity":"DEBUG","caller":"zl/sugar_test.go:24","message":"USER_FETCHED","user_id":42,"console":"user 42"}`,
+		`{"severity":"WARN","logger":"api","caller":"zl/sugar_test.go:26","message":"retry later","request_id":"1"}`,
+		`{"severity":"ERROR","logger":"api","caller":"zl/sugar_test.go:27","message":"USER_NOT_FOUND","request_id":"1","user_id":42}`,
+	}, lines[1:])
+}
+
+func TestSugaredLogger_pretty(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(PrettyOutput)
+	SetRotateFileName(filepath.Join(t.TempDir(), "app.jsonl"))
+	SetOmitKeys(TimeKey)
+	defer ResetGlobalLoggerSettings()
+	Init()
+	pretty := loadState().pretty
+	pretty.Logger.SetOutput(&buf)
+	Infow("USER_FETCHED", "user_id", 42, Console("user 42"))
+
+	sugar := New(Console("api")).Sugar()
+	sugar.Infof("fetched %d users", 3)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, "sugar_test.go:50: "+pretty.coloredLevel(InfoLevel).String()+" "+
+		pretty.coloredMsg("USER_FETCHED", InfoLevel, []zap.Field{Console("user 42")}), lines[0])
+	assert.Equal(t, "sugar_test.go:53: "+pretty.coloredLevel(InfoLevel).String()+" "+
+		pretty.coloredMsg("fetched 3 users", InfoLevel, []zap.Field{Console("api")}), lines[1])
+}
@@ -0,0 +1,228 @@
+package zl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Facility is the syslog facility. See: https://www.rfc-editor.org/rfc/rfc5424#section-6.2.1
+type Facility int
+
+// Facilities available to applications. The kernel facility (0) is not included.
+const (
+	FacilityUser Facility = iota + 1
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLpr
+	FacilityNews
+	FacilityUucp
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFtp
+)
+
+// Facilities reserved for local use.
+const (
+	FacilityLocal0 Facility = iota + 16
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// syslogSDID is the SD-ID of the structured data.
+// 32473 is the Private Enterprise Number reserved for documentation. See: RFC 5612
+const syslogSDID = "zl@32473"
+
+// SyslogOptions is the settings of the syslog sink.
+type SyslogOptions struct {
+	// Network is "udp", "tcp", "unix" or "unixgram".
+	Network string
+	// Address is "host:port" or the path of the unix socket. e.g. "localhost:514", "/dev/log"
+	Address string
+	// Facility is the syslog facility. Default is FacilityUser.
+	Facility Facility
+	// AppName is the APP-NAME field. Default is the name set by SetServiceName or the name of the executable.
+	AppName string
+	// Hostname is the HOSTNAME field. Default is the HostnameKey field of the entry or os.Hostname.
+	Hostname string
+	// StructuredData writes the fields of the entry as STRUCTURED-DATA and the message as MSG,
+	// instead of writing the JSON entry as MSG.
+	StructuredData bool
+	// DialTimeout is the timeout for connecting and for each write. Default is 5 seconds.
+	DialTimeout time.Duration
+}
+
+// SyslogWriter is a zapcore.WriteSyncer that sends the entries encoded by JSONEncoder
+// to a syslog server in RFC 5424 format. It reconnects when writing fails.
+// Stream connections (tcp and unix) are framed with octet counting (RFC 6587).
+//
+// A typical usage would be something like.
+//
+//	w, err := zl.NewSyslogWriter(zl.SyslogOptions{Network: "udp", Address: "localhost:514"})
+//	if err != nil {
+//	  log.Fatal(err)
+//	}
+//	zl.AddSink("syslog", w, zl.SinkOptions{Level: zl.InfoLevel})
+type SyslogWriter struct {
+	options  SyslogOptions
+	conn     *netConn
+	hostname string
+}
+
+// NewSyslogWriter connects to the syslog server and returns the SyslogWriter.
+func NewSyslogWriter(options SyslogOptions) (*SyslogWriter, error) {
+	if options.Facility == 0 {
+		options.Facility = FacilityUser
+	}
+	if options.AppName == "" {
+		options.AppName = appName()
+	}
+	if options.DialTimeout == 0 {
+		options.DialTimeout = 5 * time.Second
+	}
+	conn, err := dialNetConn(options.Network, options.Address, options.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	w := &SyslogWriter{options: options, conn: conn}
+	if h := getHost(); h != nil {
+		w.hostname = *h
+	}
+	return w, nil
+}
+
+// requiresJSON implements jsonSink.
+func (w *SyslogWriter) requiresJSON() {}
+
+// Write implements io.Writer. p is an entry encoded by JSONEncoder.
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	m, err := parseEntry(p)
+	if err != nil {
+		return 0, err
+	}
+	msg := w.format(m, bytes.TrimRight(p, "\n"))
+	if w.conn.isStream() {
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+	if err := w.conn.write(msg); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync implements zapcore.WriteSyncer.
+func (w *SyslogWriter) Sync() error {
+	return nil
+}
+
+// Close closes the connection.
+func (w *SyslogWriter) Close() error {
+	return w.conn.close()
+}
+
+// format returns the RFC 5424 message.
+// <PRI>VERSION SP TIMESTAMP SP HOSTNAME SP APP-NAME SP PROCID SP MSGID SP STRUCTURED-DATA [SP MSG]
+func (w *SyslogWriter) format(m map[string]interface{}, entry []byte) []byte {
+	hostname := w.options.Hostname
+	if hostname == "" {
+		hostname = entryString(m, HostnameKey)
+	}
+	if hostname == "" {
+		hostname = w.hostname
+	}
+	procID := strconv.Itoa(os.Getpid())
+	if pid, ok := m[fieldKey(PIDKey)].(json.Number); ok {
+		procID = pid.String()
+	}
+	sd, msg := "-", entry
+	if w.options.StructuredData {
+		sd, msg = syslogStructuredData(m), []byte(entryString(m, MessageKey))
+	}
+
+	buf := fmt.Appendf(nil, "<%d>1 %s %s %s %s %s %s",
+		int(w.options.Facility)*8+syslogSeverity(entryLevel(m)),
+		entryTime(m).Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeader(hostname, 255),
+		syslogHeader(w.options.AppName, 48),
+		syslogHeader(procID, 128),
+		syslogHeader(entryString(m, MessageKey), 32),
+		sd,
+	)
+	if len(msg) > 0 {
+		buf = append(append(buf, ' '), msg...)
+	}
+	return buf
+}
+
+// syslogSeverity maps the zap level to the syslog severity.
+func syslogSeverity(level zapcore.Level) int {
+	switch level {
+	case DebugLevel:
+		return 7 // debug
+	case InfoLevel:
+		return 6 // informational
+	case WarnLevel:
+		return 4 // warning
+	case ErrorLevel:
+		return 3 // error
+	default:
+		return 2 // critical
+	}
+}
+
+// syslogHeader returns the header field that consists of printable US-ASCII characters, or "-" (NILVALUE).
+func syslogHeader(s string, maxLen int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, s)
+	if len(s) > maxLen {
+		s = s[:maxLen]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// syslogStructuredData returns the fields of the entry as an SD-ELEMENT.
+// e.g. [zl@32473 severity="INFO" user_id="1"]
+func syslogStructuredData(m map[string]interface{}) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		if k != fieldKey(MessageKey) {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return "-"
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("[" + syslogSDID)
+	for _, k := range keys {
+		name := syslogHeader(strings.NewReplacer("=", "_", "]", "_", `"`, "_").Replace(k), 32)
+		b.WriteString(" " + name + `="`)
+		b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`, "]", `\]`).Replace(logfmtValue(m[k])))
+		b.WriteString(`"`)
+	}
+	b.WriteString("]")
+	return b.String()
+}
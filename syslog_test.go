@@ -0,0 +1,218 @@
+package zl
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSyslogEntry = `{"severity":"WARN","timestamp":"2026-10-17T12:00:00.123456789Z",` +
+	`"message":"SOME_WARN","hostname":"host1","pid":123,"user":"Alice \"A\""}` + "\n"
+
+func TestSyslogWriter_udp(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	w, err := NewSyslogWriter(SyslogOptions{
+		Network:  "udp",
+		Address:  conn.LocalAddr().String(),
+		Facility: FacilityLocal0,
+		AppName:  "app",
+	})
+	assert.NoError(t, err)
+	defer w.Close()
+
+	n, err := w.Write([]byte(testSyslogEntry))
+	assert.NoError(t, err)
+	assert.Equal(t, len(testSyslogEntry), n)
+	assert.NoError(t, w.Sync())
+
+	buf := make([]byte, 1024)
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err = conn.ReadFrom(buf)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"<132>1 2026-10-17T12:00:00.123456Z host1 app 123 SOME_WARN - "+strings.TrimSuffix(testSyslogEntry, "\n"),
+		string(buf[:n]),
+	)
+}
+
+func TestSyslogWriter_tcp(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	received := make(chan string, 2)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go readOctetCounted(conn, received)
+		}
+	}()
+
+	w, err := NewSyslogWriter(SyslogOptions{
+		Network:        "tcp",
+		Address:        ln.Addr().String(),
+		AppName:        "app",
+		Hostname:       "my host",
+		StructuredData: true,
+	})
+	assert.NoError(t, err)
+	defer w.Close()
+
+	_, err = w.Write([]byte(testSyslogEntry))
+	assert.NoError(t, err)
+
+	// reconnect when the connection is broken.
+	assert.NoError(t, w.conn.conn.Close())
+	_, err = w.Write([]byte(testSyslogEntry))
+	assert.NoError(t, err)
+
+	expected := `<12>1 2026-10-17T12:00:00.123456Z myhost app 123 SOME_WARN ` +
+		`[zl@32473 hostname="host1" pid="123" severity="WARN" timestamp="2026-10-17T12:00:00.123456789Z" ` +
+		`user="Alice \"A\""] SOME_WARN`
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-received:
+			assert.Equal(t, expected, msg)
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+}
+
+func TestSyslogWriter_stalled(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		// the server accepts a connection and never reads it.
+		conn, err := ln.Accept()
+		ln.Close()
+		if err != nil {
+			return
+		}
+		<-stop
+		conn.Close()
+	}()
+
+	w, err := NewSyslogWriter(SyslogOptions{
+		Network:     "tcp",
+		Address:     ln.Addr().String(),
+		DialTimeout: 50 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+	defer w.Close()
+
+	entry := []byte(`{"severity":"INFO","message":"` + strings.Repeat("a", 1<<16) + `"}`)
+	done := make(chan error)
+	go func() {
+		var err error
+		for i := 0; i < 1000 && err == nil; i++ {
+			_, err = w.Write(entry)
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("Write blocked on the stalled server")
+	}
+}
+
+func TestSyslogWriter_unixgram(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "syslog.sock")
+	conn, err := net.ListenPacket("unixgram", addr)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	w, err := NewSyslogWriter(SyslogOptions{Network: "unixgram", Address: addr})
+	assert.NoError(t, err)
+	defer w.Close()
+
+	_, err = w.Write([]byte(`{"severity":"DEBUG","message":"SOME DEBUG"}`))
+	assert.NoError(t, err)
+
+	buf := make([]byte, 1024)
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	assert.NoError(t, err)
+	assert.Contains(t, string(buf[:n]), fmt.Sprintf(" %s %d SOMEDEBUG - {", filepath.Base(os.Args[0]), os.Getpid()))
+	assert.True(t, strings.HasPrefix(string(buf[:n]), "<15>1 "))
+}
+
+func TestNewSyslogWriter_appName(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceName string
+		appName     string
+		expected    string
+	}{
+		{"executable", "", "", filepath.Base(os.Args[0])},
+		{"service name", "billing", "", "billing"},
+		{"option", "billing", "app", "app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := filepath.Join(t.TempDir(), "syslog.sock")
+			conn, err := net.ListenPacket("unixgram", addr)
+			assert.NoError(t, err)
+			defer conn.Close()
+			SetServiceName(tt.serviceName)
+			defer ResetGlobalLoggerSettings()
+
+			w, err := NewSyslogWriter(SyslogOptions{Network: "unixgram", Address: addr, AppName: tt.appName})
+			assert.NoError(t, err)
+			defer w.Close()
+			assert.Equal(t, tt.expected, w.options.AppName)
+		})
+	}
+}
+
+func TestNewSyslogWriter_error(t *testing.T) {
+	_, err := NewSyslogWriter(SyslogOptions{Network: "unix", Address: filepath.Join(t.TempDir(), "not-found")})
+	assert.Error(t, err)
+}
+
+func Test_syslogSeverity(t *testing.T) {
+	assert.Equal(t, 7, syslogSeverity(DebugLevel))
+	assert.Equal(t, 6, syslogSeverity(InfoLevel))
+	assert.Equal(t, 4, syslogSeverity(WarnLevel))
+	assert.Equal(t, 3, syslogSeverity(ErrorLevel))
+	assert.Equal(t, 2, syslogSeverity(FatalLevel))
+}
+
+// readOctetCounted reads the messages framed with octet counting (RFC 6587).
+func readOctetCounted(conn net.Conn, received chan<- string) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		size, err := r.ReadString(' ')
+		if err != nil {
+			return
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(size))
+		if err != nil {
+			return
+		}
+		buf := make([]byte, n)
+		if _, err := r.Read(buf); err != nil {
+			return
+		}
+		received <- string(buf)
+	}
+}
@@ -0,0 +1,147 @@
+package zl
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// TraceProfile is the set of field keys in which WithContext writes the trace context.
+type TraceProfile int
+
+const (
+	// OpenTelemetryTrace writes trace_id, span_id and trace_flags. It is Default setting.
+	OpenTelemetryTrace TraceProfile = iota
+	// GoogleCloudTrace writes the special fields of Google Cloud Logging.
+	// Set the project ID with SetGoogleCloudProjectID to link the entries to Cloud Trace.
+	// See: https://cloud.google.com/logging/docs/structured-logging#special-payload-fields
+	GoogleCloudTrace
+	// DatadogTrace writes dd.trace_id and dd.span_id.
+	// See: https://docs.datadoghq.com/tracing/other_telemetry/connect_logs_and_traces/opentelemetry/
+	DatadogTrace
+)
+
+// The field keys of the trace context written by OpenTelemetryTrace.
+const (
+	traceIDKey    = "trace_id"
+	spanIDKey     = "span_id"
+	traceFlagsKey = "trace_flags"
+)
+
+var (
+	traceProfile         TraceProfile
+	googleCloudProjectID string
+	recordSpanEvents     bool
+)
+
+// SetTraceProfile sets the field keys of the trace context. Default is OpenTelemetryTrace.
+func SetTraceProfile(profile TraceProfile) {
+	mu.Lock()
+	defer mu.Unlock()
+	traceProfile = profile
+}
+
+// SetGoogleCloudProjectID sets the project ID used in the trace field of GoogleCloudTrace.
+// e.g. "projects/my-project/traces/4bf92f3577b34da6a3ce929d0e0e4736"
+func SetGoogleCloudProjectID(projectID string) {
+	mu.Lock()
+	defer mu.Unlock()
+	googleCloudProjectID = projectID
+}
+
+// SetRecordSpanEvents records ERROR and higher level logs as events of the span
+// in the context passed to WithContext.
+func SetRecordSpanEvents(enabled bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	recordSpanEvents = enabled
+}
+
+// WithContext returns a new Logger with the trace context of the OpenTelemetry span in the ctx.
+// If the ctx has no valid span context, the Logger has no trace fields.
+//
+// A typical usage would be something like.
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//	  logger := zl.WithContext(r.Context())
+//	  logger.Info("REQUEST_RECEIVED")
+//	}
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	span := trace.SpanFromContext(ctx)
+	sc := span.SpanContext()
+	if !sc.IsValid() {
+		return l.clone()
+	}
+	clone := l.With(loadState().traceFields(sc)...)
+	clone.span = span
+	return clone
+}
+
+// WithContext returns a new Logger with the trace context of the OpenTelemetry span in the ctx.
+// It uses the global logger initialized by Init.
+func WithContext(ctx context.Context) *Logger {
+	return New().WithContext(ctx)
+}
+
+func (s *state) traceFields(sc trace.SpanContext) []zap.Field {
+	traceID, spanID := sc.TraceID(), sc.SpanID()
+	switch s.traceProfile {
+	case GoogleCloudTrace:
+		t := traceID.String()
+		if s.googleCloudProjectID != "" {
+			t = fmt.Sprintf("projects/%s/traces/%s", s.googleCloudProjectID, t)
+		}
+		return []zap.Field{
+			zap.String("logging.googleapis.com/trace", t),
+			zap.String("logging.googleapis.com/spanId", spanID.String()),
+			zap.Bool("logging.googleapis.com/trace_sampled", sc.IsSampled()),
+		}
+	case DatadogTrace:
+		// Datadog uses the lower 64 bits of the trace ID in decimal.
+		return []zap.Field{
+			zap.String("dd.trace_id", strconv.FormatUint(binary.BigEndian.Uint64(traceID[8:]), 10)),
+			zap.String("dd.span_id", strconv.FormatUint(binary.BigEndian.Uint64(spanID[:]), 10)),
+		}
+	default:
+		return []zap.Field{
+			zap.String(traceIDKey, traceID.String()),
+			zap.String(spanIDKey, spanID.String()),
+			zap.Int(traceFlagsKey, int(sc.TraceFlags())),
+		}
+	}
+}
+
+// addSpanEvent records the log as an event of the span when SetRecordSpanEvents is enabled.
+func (l *Logger) addSpanEvent(message string, level zapcore.Level, err error, fields []zap.Field) {
+	s := loadState()
+	if !s.recordSpanEvents || l.span == nil || level < ErrorLevel || level < s.level || !l.span.IsRecording() {
+		return
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	for i := range fields {
+		fields[i].AddTo(enc)
+	}
+	// the span has the trace context, and the error is recorded as exception.message.
+	for _, f := range s.traceFields(l.span.SpanContext()) {
+		delete(enc.Fields, f.Key)
+	}
+	if err != nil {
+		delete(enc.Fields, "error")
+		delete(enc.Fields, "errorVerbose")
+	}
+	attrs := make([]attribute.KeyValue, 0, len(enc.Fields)+2)
+	attrs = append(attrs, attribute.String("log.severity", level.CapitalString()))
+	if err != nil {
+		attrs = append(attrs, attribute.String("exception.message", err.Error()))
+	}
+	for _, kv := range otlpKeyValues(enc.Fields) {
+		attrs = append(attrs, attribute.String(kv.key, logfmtValue(kv.value)))
+	}
+	l.span.AddEvent(message, trace.WithAttributes(attrs...))
+}
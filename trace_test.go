@@ -0,0 +1,137 @@
+package zl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type testSpanEvent struct {
+	name  string
+	attrs []attribute.KeyValue
+}
+
+type testSpan struct {
+	noop.Span
+	sc     trace.SpanContext
+	events []testSpanEvent
+}
+
+func (s *testSpan) SpanContext() trace.SpanContext { return s.sc }
+
+func (s *testSpan) IsRecording() bool { return true }
+
+func (s *testSpan) AddEvent(name string, options ...trace.EventOption) {
+	cfg := trace.NewEventConfig(options...)
+	s.events = append(s.events, testSpanEvent{name, cfg.Attributes()})
+}
+
+func newTestSpan(t *testing.T) *testSpan {
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	assert.NoError(t, err)
+	return &testSpan{sc: trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})}
+}
+
+func TestWithContext(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(ConsoleOutput)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, StacktraceKey, PIDKey)
+	AddSink("buffer", zapcore.AddSync(&buf), SinkOptions{})
+	defer ResetGlobalLoggerSettings()
+	Init()
+
+	span := newTestSpan(t)
+	ctx := trace.ContextWithSpan(context.Background(), span)
+	WithContext(ctx).Info("PACKAGE_LOGGER")
+	New(zap.String("user", "Alice")).WithContext(ctx).Info("NEW_LOGGER")
+	WithContext(context.Background()).Info("NO_SPAN")
+
+	assert.Equal(t, ""+
+		`{"severity":"INFO","message":"PACKAGE_LOGGER",`+
+		`"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7","trace_flags":1}`+"\n"+
+		`{"severity":"INFO","message":"NEW_LOGGER","user":"Alice",`+
+		`"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7","trace_flags":1}`+"\n"+
+		`{"severity":"INFO","message":"NO_SPAN"}`+"\n",
+		buf.String(),
+	)
+	assert.Empty(t, span.events)
+}
+
+func TestSetTraceProfile(t *testing.T) {
+	sc := newTestSpan(t).sc
+	tests := []struct {
+		name      string
+		profile   TraceProfile
+		projectID string
+		expected  []zap.Field
+	}{
+		{
+			name:    "OpenTelemetry",
+			profile: OpenTelemetryTrace,
+			expected: []zap.Field{
+				zap.String("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"),
+				zap.String("span_id", "00f067aa0ba902b7"),
+				zap.Int("trace_flags", 1),
+			},
+		},
+		{
+			name:      "GoogleCloud",
+			profile:   GoogleCloudTrace,
+			projectID: "my-project",
+			expected: []zap.Field{
+				zap.String("logging.googleapis.com/trace", "projects/my-project/traces/4bf92f3577b34da6a3ce929d0e0e4736"),
+				zap.String("logging.googleapis.com/spanId", "00f067aa0ba902b7"),
+				zap.Bool("logging.googleapis.com/trace_sampled", true),
+			},
+		},
+		{
+			name:    "Datadog",
+			profile: DatadogTrace,
+			expected: []zap.Field{
+				zap.String("dd.trace_id", "11803532876627986230"),
+				zap.String("dd.span_id", "67667974448284343"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &state{traceProfile: tt.profile, googleCloudProjectID: tt.projectID}
+			assert.Equal(t, tt.expected, s.traceFields(sc))
+		})
+	}
+}
+
+func TestSetRecordSpanEvents(t *testing.T) {
+	SetOutput(ConsoleOutput)
+	SetRecordSpanEvents(true)
+	defer ResetGlobalLoggerSettings()
+	Init()
+
+	span := newTestSpan(t)
+	logger := WithContext(trace.ContextWithSpan(context.Background(), span))
+	logger.Warn("WARN_MESSAGE")
+	logger.Err("ERROR_MESSAGE", errors.New("some error"), zap.Int("count", 1))
+
+	assert.Equal(t, []testSpanEvent{{
+		name: "ERROR_MESSAGE",
+		attrs: []attribute.KeyValue{
+			attribute.String("log.severity", "ERROR"),
+			attribute.String("exception.message", "some error"),
+			attribute.String("count", "1"),
+		},
+	}}, span.events)
+}
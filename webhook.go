@@ -0,0 +1,270 @@
+package zl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebhookFormat is the format of the webhook payload.
+type WebhookFormat int
+
+const (
+	// WebhookGeneric posts the error fields and the text as JSON. It is Default setting.
+	WebhookGeneric WebhookFormat = iota
+	// WebhookSlack posts the text in the format of Slack incoming webhooks.
+	// See: https://api.slack.com/messaging/webhooks
+	WebhookSlack
+)
+
+// WebhookOptions is the settings of the webhook alert.
+type WebhookOptions struct {
+	// URL is the URL of the webhook.
+	URL string
+	// Format is the format of the payload. Default is WebhookGeneric.
+	Format WebhookFormat
+	// Window is the period of the throttling. Default is 5 minutes.
+	// The first error of a group is posted immediately, and the following errors in the window are
+	// posted as a summary when the window ends. e.g. "JSON_UNMARSHAL_ERROR occurred 37 times in 5m"
+	Window time.Duration
+	// QueueSize is the maximum number of errors waiting to be processed. Default is 100.
+	// Errors are dropped when the queue is full, so logging never blocks.
+	QueueSize int
+	// Headers are added to the requests.
+	Headers map[string]string
+	// Timeout is the timeout of a request. Default is 10 seconds.
+	Timeout time.Duration
+}
+
+// WebhookWriter is a zapcore.WriteSyncer that posts an alert to the webhook
+// when an entry of ERROR or higher level is written. Lower level entries are ignored.
+// The entries are grouped by the severity, message, caller and error as in the error report of PrettyOutput,
+// and each group is throttled.
+//
+// A typical usage would be something like.
+//
+//	w := zl.NewWebhookWriter(zl.WebhookOptions{URL: slackWebhookURL, Format: zl.WebhookSlack})
+//	zl.AddSink("slack", w, zl.SinkOptions{Level: zl.ErrorLevel})
+//	zl.Init()
+//	defer w.Close()
+type WebhookWriter struct {
+	options WebhookOptions
+	client  *http.Client
+	queue   chan *ErrorLog
+	flush   chan chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+	groups  map[string]*webhookGroup
+}
+
+// webhookGroup is a group of the errors in the current window.
+type webhookGroup struct {
+	last      *ErrorLog
+	count     int
+	windowEnd time.Time
+}
+
+// NewWebhookWriter returns the WebhookWriter.
+func NewWebhookWriter(options WebhookOptions) *WebhookWriter {
+	if options.Window <= 0 {
+		options.Window = 5 * time.Minute
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = 100
+	}
+	if options.Timeout == 0 {
+		options.Timeout = 10 * time.Second
+	}
+	w := &WebhookWriter{
+		options: options,
+		client:  &http.Client{Timeout: options.Timeout},
+		queue:   make(chan *ErrorLog, options.QueueSize),
+		flush:   make(chan chan struct{}),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		groups:  make(map[string]*webhookGroup),
+	}
+	go w.run()
+	return w
+}
+
+// requiresJSON implements jsonSink.
+func (w *WebhookWriter) requiresJSON() {}
+
+// Write implements io.Writer. p is an entry encoded by JSONEncoder.
+func (w *WebhookWriter) Write(p []byte) (int, error) {
+	var el *ErrorLog
+	if err := json.Unmarshal(p, &el); err != nil {
+		return 0, err
+	}
+	if el.Severity < ErrorLevel {
+		return len(p), nil
+	}
+	select {
+	case <-w.done:
+		return 0, errors.New("the webhook writer is closed")
+	default:
+	}
+	select {
+	case w.queue <- el:
+		return len(p), nil
+	default:
+		return 0, fmt.Errorf("the webhook queue is full. the alert is dropped: %s", el.Message)
+	}
+}
+
+// Sync implements zapcore.WriteSyncer. It posts the queued alerts and waits for them.
+func (w *WebhookWriter) Sync() error {
+	ch := make(chan struct{})
+	select {
+	case w.flush <- ch:
+		<-ch
+	case <-w.stopped:
+	}
+	return nil
+}
+
+// Close posts the queued alerts and the summaries of the current windows, and stops the goroutine.
+func (w *WebhookWriter) Close() error {
+	w.once.Do(func() {
+		close(w.done)
+	})
+	<-w.stopped
+	return nil
+}
+
+func (w *WebhookWriter) run() {
+	defer close(w.stopped)
+	interval := time.Second
+	if w.options.Window < interval {
+		interval = w.options.Window
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case el := <-w.queue:
+			w.receive(el)
+		case <-ticker.C:
+			w.endWindows(currentTime())
+		case ch := <-w.flush:
+			w.drain()
+			close(ch)
+		case <-w.done:
+			w.drain()
+			w.endWindows(time.Time{})
+			return
+		}
+	}
+}
+
+func (w *WebhookWriter) drain() {
+	for {
+		select {
+		case el := <-w.queue:
+			w.receive(el)
+		default:
+			return
+		}
+	}
+}
+
+// receive posts the first error of the group, or counts it in the current window.
+func (w *WebhookWriter) receive(el *ErrorLog) {
+	key := el.groupKey()
+	if g, ok := w.groups[key]; ok {
+		g.last = el
+		g.count++
+		return
+	}
+	w.groups[key] = &webhookGroup{last: el, windowEnd: currentTime().Add(w.options.Window)}
+	w.post(el, 0)
+}
+
+// endWindows posts the summaries of the windows that end before now. A zero now ends all windows.
+func (w *WebhookWriter) endWindows(now time.Time) {
+	for key, g := range w.groups {
+		if !now.IsZero() && now.Before(g.windowEnd) {
+			continue
+		}
+		if g.count == 0 {
+			delete(w.groups, key)
+			continue
+		}
+		w.post(g.last, g.count)
+		g.count = 0
+		g.windowEnd = now.Add(w.options.Window)
+	}
+}
+
+// post posts the alert. count is the number of the errors in the window, or zero for the first error.
+func (w *WebhookWriter) post(el *ErrorLog, count int) {
+	body, err := json.Marshal(w.payload(el, count))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, w.options.URL, bytes.NewReader(body))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.options.Headers {
+		req.Header.Set(k, v)
+	}
+	if err := postRequest(w.client, req); err != nil {
+		log.Println(err)
+	}
+}
+
+func (w *WebhookWriter) payload(el *ErrorLog, count int) interface{} {
+	text := fmt.Sprintf("%s: %s", el.Severity.CapitalString(), el.Message)
+	if el.Error != "" {
+		text += ": " + el.Error
+	}
+	if el.Caller != "" {
+		text += fmt.Sprintf(" (%s)", el.Caller)
+	}
+	if count > 0 {
+		text = fmt.Sprintf("%s occurred %d times in %s", el.Message, count, shortDuration(w.options.Window))
+	}
+
+	if w.options.Format == WebhookSlack {
+		return map[string]string{"text": text}
+	}
+	p := map[string]interface{}{
+		"text":      text,
+		"severity":  el.Severity.CapitalString(),
+		"message":   el.Message,
+		"error":     el.Error,
+		"caller":    el.Caller,
+		"timestamp": el.Timestamp,
+	}
+	if count > 0 {
+		p["count"] = count
+		p["window"] = shortDuration(w.options.Window)
+	}
+	return p
+}
+
+// shortDuration formats the duration without the zero units. e.g. "5m", "1h30m"
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
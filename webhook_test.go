@@ -0,0 +1,112 @@
+package zl
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type webhookServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	payloads []map[string]interface{}
+}
+
+func newWebhookServer(t *testing.T) *webhookServer {
+	s := &webhookServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		b, _ := io.ReadAll(r.Body)
+		var p map[string]interface{}
+		assert.NoError(t, json.Unmarshal(b, &p))
+		s.mu.Lock()
+		s.payloads = append(s.payloads, p)
+		s.mu.Unlock()
+	}))
+	return s
+}
+
+func (s *webhookServer) get() []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]interface{}{}, s.payloads...)
+}
+
+func TestWebhookWriter(t *testing.T) {
+	server := newWebhookServer(t)
+	defer server.Close()
+
+	w := NewWebhookWriter(WebhookOptions{URL: server.URL, Window: 200 * time.Millisecond})
+	defer w.Close()
+	SetOutput(ConsoleOutput)
+	SetOmitKeys(TimeKey, FunctionKey, VersionKey, HostnameKey, PIDKey)
+	AddSink("webhook", w, SinkOptions{Level: InfoLevel})
+	defer ResetGlobalLoggerSettings()
+	Init()
+
+	Info("INFO_MESSAGE")
+	for i := 0; i < 3; i++ {
+		Err("JSON_UNMARSHAL_ERROR", errors.New("unexpected end of JSON input"))
+	}
+	Error("OTHER_ERROR")
+	assert.NoError(t, w.Sync())
+
+	payloads := server.get()
+	assert.Len(t, payloads, 2)
+	assert.Equal(t, "ERROR", payloads[0]["severity"])
+	assert.Equal(t, "JSON_UNMARSHAL_ERROR", payloads[0]["message"])
+	assert.Equal(t, "unexpected end of JSON input", payloads[0]["error"])
+	assert.Contains(t, payloads[0]["caller"], "webhook_test.go")
+	assert.Contains(t, payloads[0]["text"], "ERROR: JSON_UNMARSHAL_ERROR: unexpected end of JSON input (")
+	assert.NotContains(t, payloads[0], "count")
+	assert.Equal(t, "OTHER_ERROR", payloads[1]["message"])
+
+	// the summary of the window.
+	assert.Eventually(t, func() bool { return len(server.get()) == 3 }, time.Second, 10*time.Millisecond)
+	summary := server.get()[2]
+	assert.Equal(t, "JSON_UNMARSHAL_ERROR occurred 2 times in 200ms", summary["text"])
+	assert.Equal(t, float64(2), summary["count"])
+	assert.Equal(t, "200ms", summary["window"])
+}
+
+func TestWebhookWriter_slack(t *testing.T) {
+	server := newWebhookServer(t)
+	defer server.Close()
+
+	w := NewWebhookWriter(WebhookOptions{URL: server.URL, Format: WebhookSlack, Window: time.Hour})
+	for _, entry := range []string{
+		`{"severity":"ERROR","message":"SOME_ERROR","caller":"zl/main.go:10"}`,
+		`{"severity":"ERROR","message":"SOME_ERROR","caller":"zl/main.go:10"}`,
+		`{"severity":"FATAL","message":"FATAL_ERROR","error":"some error"}`,
+	} {
+		_, err := w.Write([]byte(entry))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Sync())
+	assert.Equal(t, []map[string]interface{}{
+		{"text": "ERROR: SOME_ERROR (zl/main.go:10)"},
+		{"text": "FATAL: FATAL_ERROR: some error"},
+	}, server.get())
+
+	// the summaries are posted when closing.
+	assert.NoError(t, w.Close())
+	assert.Equal(t, map[string]interface{}{"text": "SOME_ERROR occurred 1 times in 1h"}, server.get()[2])
+	assert.Len(t, server.get(), 3)
+	_, err := w.Write([]byte(`{"severity":"ERROR","message":"SOME_ERROR"}`))
+	assert.Error(t, err)
+}
+
+func Test_shortDuration(t *testing.T) {
+	assert.Equal(t, "5m", shortDuration(5*time.Minute))
+	assert.Equal(t, "1h", shortDuration(time.Hour))
+	assert.Equal(t, "1h30m", shortDuration(90*time.Minute))
+	assert.Equal(t, "30s", shortDuration(30*time.Second))
+	assert.Equal(t, "1m30s", shortDuration(90*time.Second))
+}
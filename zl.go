@@ -5,6 +5,7 @@
 package zl
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -13,6 +14,7 @@ import (
 	"os/signal"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 
 	"github.com/samber/lo"
@@ -25,66 +27,35 @@ const (
 )
 
 var (
-	once           sync.Once
-	pretty         *prettyLogger
-	zapLogger      *zap.Logger
-	encoderConfig  *zapcore.EncoderConfig
-	internalLogger *zap.Logger
-	outputType     Output
-	version        string
-	severityLevel  zapcore.Level // Default is InfoLevel
-	callerEncoder  zapcore.CallerEncoder
-	consoleFields  = []string{consoleFieldDefault}
-	omitKeys       []Key
-	fieldKeys      = make(map[Key]string)
-	isStdOut       bool
-	separator      = " "
-	pid            int
-	isTest         bool
+	mu            sync.Mutex // mu guards the settings below, and is held while the loggers are built from them.
+	loaded        atomic.Pointer[state]
+	outputType    Output
+	version       string
+	serviceName   string
+	severityLevel zapcore.Level // Default is InfoLevel
+	development   *bool         // Default is true only in PrettyOutput
+	callerEncoder zapcore.CallerEncoder
+	consoleFields = []string{consoleFieldDefault}
+	omitKeys      []Key
+	fieldKeys     atomic.Pointer[map[Key]string] // fieldKeys is replaced, not modified, as the sinks read it while writing.
+	isStdOut      bool
+	separator     = " "
 )
 
-type fatalHook struct{}
-
-func (f fatalHook) OnWrite(_ *zapcore.CheckedEntry, _ []zapcore.Field) {
-	pretty.showErrorReport(fileName, pid)
-	if isTest {
-		fmt.Println("os.Exit(1) called.")
-	} else {
-		os.Exit(1)
-	}
-}
-
 // Init initializes the logger.
+// The settings must be set before Init, or changed later with Reconfigure.
 func Init() {
-	once.Do(func() {
-		encoderConfig = newEncoderConfig()
-		zapLogger = newLogger(encoderConfig)
-		if outputType == PrettyOutput || isTest {
-			pretty = newPrettyLogger(getConsoleOutput(), os.Stderr)
-			zapLogger = zapLogger.WithOptions(zap.WithFatalHook(fatalHook{}))
-		}
-
-		encInternal := newEncoderConfig()
-		encInternal.EncodeCaller = zapcore.ShortCallerEncoder
-		internalLogger = newLogger(encInternal)
-
-		var p, f string
-		if pid != 0 {
-			p = fmt.Sprintf(", PID: %d", pid)
-		}
-		if outputType == PrettyOutput || outputType == ConsoleAndFileOutput {
-			f = fmt.Sprintf(", File: %s", fileName)
-		}
+	mu.Lock()
+	if loaded.Load() != nil {
+		mu.Unlock()
+		return
+	}
+	s := newState()
+	loaded.Store(s)
+	mu.Unlock()
 
-		c := fmt.Sprintf(
-			"Severity: %s, Output: %s%s%s",
-			severityLevel.CapitalString(),
-			outputType.String(),
-			f,
-			p,
-		)
-		iDebug("INIT_LOGGER", Console(c))
-	})
+	iDebug("INIT_LOGGER", Console(s.String()))
+	s.removeExceedingTotalSize()
 }
 
 func newEncoderConfig() *zapcore.EncoderConfig {
@@ -106,25 +77,28 @@ func newEncoderConfig() *zapcore.EncoderConfig {
 }
 
 func fieldKey(key Key) string {
-	value, ok := fieldKeys[key]
-	if !ok {
-		return string(key)
+	if m := fieldKeys.Load(); m != nil {
+		if value, ok := (*m)[key]; ok {
+			return value
+		}
 	}
-	return value
+	return string(key)
 }
 
+// loggerOptions returns the options of the zap loggers.
 // See https://pkg.go.dev/go.uber.org/zap
-func newLogger(enc *zapcore.EncoderConfig) *zap.Logger {
-	core := zapcore.NewCore(
-		zapcore.NewJSONEncoder(*enc),
-		zapcore.NewMultiWriteSyncer(getSyncers()...),
-		severityLevel,
-	)
-	return zap.New(core,
+func loggerOptions() []zap.Option {
+	opts := []zap.Option{
 		zap.AddCallerSkip(1),
 		zap.AddCaller(),
 		zap.AddStacktrace(zapcore.ErrorLevel),
-	).With(getAdditionalFields()...)
+		zap.WithFatalHook(fatalHook{}),
+		zap.WithPanicHook(panicHook{}),
+	}
+	if isDevelopment() {
+		opts = append(opts, zap.Development())
+	}
+	return opts
 }
 
 func setOmitKeys(enc *zapcore.EncoderConfig) {
@@ -150,14 +124,13 @@ func setOmitKeys(enc *zapcore.EncoderConfig) {
 
 func getAdditionalFields() (fields []zapcore.Field) {
 	if !lo.Contains(omitKeys, VersionKey) {
-		fields = append(fields, zap.String(string(VersionKey), GetVersion()))
+		fields = append(fields, zap.String(string(VersionKey), getVersion()))
 	}
 	if !lo.Contains(omitKeys, HostnameKey) {
 		fields = append(fields, zap.String(string(HostnameKey), *getHost()))
 	}
 	if !lo.Contains(omitKeys, PIDKey) {
-		pid = os.Getpid()
-		fields = append(fields, zap.Int(string(PIDKey), pid))
+		fields = append(fields, zap.Int(string(PIDKey), os.Getpid()))
 	}
 	return fields
 }
@@ -165,6 +138,12 @@ func getAdditionalFields() (fields []zapcore.Field) {
 // GetVersion return version when version is set.
 // or return git commit hash when version is not set.
 func GetVersion() string {
+	mu.Lock()
+	defer mu.Unlock()
+	return getVersion()
+}
+
+func getVersion() string {
 	if version != "" {
 		return version
 	}
@@ -185,30 +164,36 @@ func GetVersion() string {
 //
 // An error will occur if zap's Sync is executed when the output destination is console.
 // (See: https://github.com/uber-go/zap/issues/880 )
-// Therefore, Sync is executed only when console is not included in the zap output destination.
+// Therefore, the console is not synced, while the files, the sinks and the hooks are synced in all output types.
 func Sync() {
-	if outputType != PrettyOutput && outputType != FileOutput {
-		return
-	}
-	if err := zapLogger.Sync(); err != nil {
+	s := loadState()
+	s.syncBuffers()
+	if err := s.zapLogger.Sync(); err != nil {
 		log.Println(err)
 	}
-	if outputType == PrettyOutput {
-		pretty.showErrorReport(fileName, pid)
+	if s.pretty != nil {
+		s.pretty.showErrorReport(s.reportFileName, s.pid)
 	}
 }
 
 // SyncWhenStop flush log buffer. when interrupt or terminated.
+// It is also needed in ConsoleOutput to flush the sinks.
 func SyncWhenStop() {
-	if outputType != PrettyOutput && outputType != FileOutput {
+	mu.Lock()
+	skip := outputType != PrettyOutput && outputType != FileOutput && !isBuffered && len(sinks) == 0
+	mu.Unlock()
+	if skip {
 		return
 	}
 
-	c := make(chan os.Signal, 1)
-
+	c, done := notifySignals(syscall.SIGINT, syscall.SIGTERM)
 	go func() {
-		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
-		s := <-c
+		var s os.Signal
+		select {
+		case s = <-c:
+		case <-done:
+			return
+		}
 
 		sigCode := 0
 		switch s.String() {
@@ -221,14 +206,85 @@ func SyncWhenStop() {
 		iDebug(fmt.Sprintf("GOT_SIGNAL_%v", strings.ToUpper(s.String())))
 		Sync() // flush log buffer
 
-		if isTest {
-			fmt.Printf("os.Exit(%d) called.", 128+sigCode)
-		} else {
-			os.Exit(128 + sigCode)
+		exit(128 + sigCode)
+	}()
+}
+
+// RotateWhenHangup rotates the log file when the process receives SIGHUP.
+// Use this to reopen the log file after it was moved by an external tool such as logrotate.
+func RotateWhenHangup() {
+	mu.Lock()
+	skip := outputType == ConsoleOutput
+	mu.Unlock()
+	if skip {
+		return
+	}
+
+	c, done := notifySignals(syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-c:
+				iDebug("GOT_SIGNAL_HANGUP")
+				if err := Rotate(); err != nil {
+					log.Println(err)
+				}
+			case <-done:
+				return
+			}
 		}
 	}()
 }
 
+// signalWatcher is the signals watched by the goroutine of SyncWhenStop or RotateWhenHangup.
+type signalWatcher struct {
+	c    chan os.Signal
+	done chan struct{}
+}
+
+var signalWatchers []*signalWatcher
+
+// notifySignals relays the signals to the channel until the done channel is closed by Close.
+func notifySignals(sig ...os.Signal) (<-chan os.Signal, <-chan struct{}) {
+	w := &signalWatcher{c: make(chan os.Signal, 1), done: make(chan struct{})}
+	signal.Notify(w.c, sig...)
+	mu.Lock()
+	defer mu.Unlock()
+	signalWatchers = append(signalWatchers, w)
+	return w.c, w.done
+}
+
+// stopSignalWatchers stops relaying the signals and the goroutines. mu must be held.
+func stopSignalWatchers() {
+	for _, w := range signalWatchers {
+		signal.Stop(w.c)
+		close(w.done)
+	}
+	signalWatchers = nil
+}
+
+// Close flushes the buffered entries, stops the goroutines started by SyncWhenStop and RotateWhenHangup,
+// and closes the log files and the sinks that implement io.Closer. e.g. OTLPWriter or SyslogWriter.
+// The entries logged after Close are discarded. Use Reconfigure to write them again.
+// The closed sinks are removed, so add new ones with AddSink in the configure function of Reconfigure.
+// It is also useful in tests to release the files before the temporary directory is removed.
+func Close() error {
+	mu.Lock()
+	s := loaded.Swap(emptyState)
+	stopSignalWatchers()
+	closers := removeClosers()
+	mu.Unlock()
+
+	var errs []error
+	if s != nil {
+		errs = append(errs, s.close())
+	}
+	for _, c := range closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
 func getHost() *string {
 	ret, err := os.Hostname()
 	if err != nil {
@@ -245,18 +301,42 @@ func getCallerEncoder() zapcore.CallerEncoder {
 	return zapcore.ShortCallerEncoder
 }
 
-func getSyncers() (syncers []zapcore.WriteSyncer) {
+// getCores returns the cores of each output destination, which are combined with zapcore.NewTee.
+func getCores(enc *zapcore.EncoderConfig) (cores []zapcore.Core) {
+	encoder := zapcore.NewJSONEncoder(*enc)
 	switch outputType {
 	case PrettyOutput, FileOutput:
-		syncers = append(syncers, zapcore.AddSync(newRotator()))
+		cores = append(cores, zapcore.NewCore(encoder, getFileSyncer(), mainFileEnabler()))
 	case ConsoleAndFileOutput:
-		syncers = append(syncers, zapcore.AddSync(getConsoleOutput()), zapcore.AddSync(newRotator()))
+		cores = append(cores,
+			zapcore.NewCore(encoder, consoleSyncer{getConsoleOutput()}, severityLevel),
+			zapcore.NewCore(encoder, getFileSyncer(), mainFileEnabler()),
+		)
 	case ConsoleOutput:
-		syncers = append(syncers, zapcore.AddSync(getConsoleOutput()))
+		cores = append(cores, zapcore.NewCore(encoder, consoleSyncer{getConsoleOutput()}, severityLevel))
+	}
+	for _, f := range levelFiles {
+		cores = append(cores, zapcore.NewCore(encoder, f.getSyncer(), f.enabler()))
+	}
+	for _, s := range sinks {
+		cores = append(cores, s.newCore(enc))
+	}
+	for _, h := range hooks {
+		cores = append(cores, h.newCore())
 	}
 	return
 }
 
+// consoleSyncer is the console written by the zap cores. It is not synced. See: Sync
+type consoleSyncer struct {
+	io.Writer
+}
+
+// Sync implements zapcore.WriteSyncer.
+func (consoleSyncer) Sync() error {
+	return nil
+}
+
 func getConsoleOutput() io.Writer {
 	if isStdOut {
 		return os.Stdout
@@ -268,18 +348,22 @@ func getConsoleOutput() io.Writer {
 // ResetGlobalLoggerSettings resets global logger settings.
 // This is convenient for use in tests, etc.
 func ResetGlobalLoggerSettings() {
-	once = sync.Once{}
-	pretty = nil
-	zapLogger = nil
-	encoderConfig = nil
-	internalLogger = nil
+	mu.Lock()
+	defer mu.Unlock()
+	if s := loaded.Swap(nil); s != nil {
+		if err := s.close(); err != nil {
+			log.Println(err)
+		}
+	}
+	stopSignalWatchers()
 	outputType = PrettyOutput
 	version = ""
+	serviceName = ""
 	severityLevel = zapcore.InfoLevel
 	callerEncoder = nil
 	consoleFields = []string{consoleFieldDefault}
 	omitKeys = nil
-	fieldKeys = make(map[Key]string)
+	fieldKeys.Store(nil)
 	isStdOut = false
 	separator = " "
 	fileName = ""
@@ -288,6 +372,24 @@ func ResetGlobalLoggerSettings() {
 	maxAge = 0
 	localTime = false
 	compress = false
+	rotateInterval = NoInterval
+	maxTotalSize = 0
+	levelFiles = nil
+	sinks = nil
+	hooks = nil
+	development = nil
+	fatalBehavior = FatalBehavior{}
+	initMode = ExplicitInit
+	shutdownFuncs = nil
+	traceProfile = OpenTelemetryTrace
+	googleCloudProjectID = ""
+	recordSpanEvents = false
+	buffers = nil
+	isBuffered = false
+	bufferSize = 0
+	flushInterval = 0
+	logFile = nil
+	logFileWriter = nil
 }
 
 // Cleanup
@@ -296,8 +398,3 @@ func ResetGlobalLoggerSettings() {
 func Cleanup() {
 	ResetGlobalLoggerSettings()
 }
-
-// SetIsTest sets isTest flag to true.
-func SetIsTest() {
-	isTest = true
-}
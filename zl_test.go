@@ -1,8 +1,14 @@
 package zl
 
 import (
-	"github.com/stretchr/testify/assert"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestResetGlobalLoggerSettings(t *testing.T) {
@@ -16,3 +22,94 @@ func TestResetCleanup(t *testing.T) {
 	Cleanup()
 	assert.Equal(t, PrettyOutput, outputType)
 }
+
+type closingSink struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (s *closingSink) Sync() error {
+	return nil
+}
+
+func (s *closingSink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestClose(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "app.jsonl")
+	sink := &closingSink{}
+	SetOutput(FileOutput)
+	SetRotateFileName(fileName)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, PIDKey)
+	SetBufferedWrite(0, 0)
+	AddSink("closing", sink, SinkOptions{})
+	AddSink("stderr", os.Stderr, SinkOptions{Level: FatalLevel})
+	defer ResetGlobalLoggerSettings()
+	Init()
+	SyncWhenStop()
+	RotateWhenHangup()
+	l := New()
+
+	Info("BEFORE_CLOSE")
+	assert.NoError(t, Close())
+	Info("AFTER_CLOSE")
+	l.Info("AFTER_CLOSE")
+	assert.NoError(t, l.Close())
+
+	b, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"severity":"INFO","message":"BEFORE_CLOSE"}`, strings.TrimSpace(string(b)))
+	assert.Equal(t, `{"severity":"INFO","message":"BEFORE_CLOSE"}`, strings.TrimSpace(sink.String()))
+	assert.True(t, sink.closed)
+	assert.Empty(t, signalWatchers)
+	assert.Same(t, emptyState, loadState())
+	assert.Len(t, sinks, 1)
+}
+
+func TestClose_reconfigure(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "app.jsonl")
+	closed, reopened := &closingSink{}, &closingSink{}
+	SetOutput(FileOutput)
+	SetRotateFileName(fileName)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, PIDKey)
+	AddSink("closing", closed, SinkOptions{})
+	defer ResetGlobalLoggerSettings()
+	Init()
+	assert.NoError(t, Close())
+
+	Reconfigure(func() {
+		AddSink("closing", reopened, SinkOptions{})
+	})
+	Info("AFTER_RECONFIGURE")
+	Sync()
+
+	b, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `{"severity":"INFO","message":"AFTER_RECONFIGURE"}`)
+	assert.Empty(t, closed.String())
+	assert.Equal(t, `{"severity":"INFO","message":"AFTER_RECONFIGURE"}`, strings.TrimSpace(reopened.String()))
+	assert.False(t, reopened.closed)
+}
+
+func TestSync_sinks(t *testing.T) {
+	var received [][]byte
+	w := newBatchWriter(BatchOptions{Size: 100, Interval: time.Hour}, func(entries [][]byte) error {
+		received = append(received, entries...)
+		return nil
+	})
+	defer w.Close()
+	SetOutput(ConsoleOutput)
+	SetOmitKeys(TimeKey, CallerKey, FunctionKey, VersionKey, HostnameKey, PIDKey)
+	AddSink("batch", w, SinkOptions{})
+	defer ResetGlobalLoggerSettings()
+	Init()
+	SyncWhenStop()
+
+	Info("INFO_MESSAGE")
+	assert.Empty(t, received)
+	Sync()
+	assert.Equal(t, [][]byte{[]byte(`{"severity":"INFO","message":"INFO_MESSAGE"}` + "\n")}, received)
+	assert.Len(t, signalWatchers, 1)
+}